@@ -0,0 +1,75 @@
+// Command sweeper destroys the leftovers of test runs that were killed before they could tear down, identified by
+// their RandomIdentifier or by age.
+//
+//	sweeper --identifier abc123 --bucket my-tfstate --state-dir '/tmp/atmos-test-helper*/state'
+//	sweeper --older-than 24h --bucket my-tfstate --dry-run
+//	sweeper --older-than 24h --sweep-stale-identifiers --bucket my-tfstate
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/log"
+	"github.com/cloudposse/test-helpers/pkg/sweeper"
+	flag "github.com/spf13/pflag"
+)
+
+func main() {
+	options := &sweeper.Options{}
+	flag.StringSliceVar(&options.Identifiers, "identifier", nil, "RandomIdentifier of a test run to sweep, may be repeated")
+	flag.DurationVar(&options.OlderThan, "older-than", 0, "Sweep leftovers last modified longer ago than this, e.g. 24h")
+	flag.BoolVar(&options.SweepStaleIdentifiers, "sweep-stale-identifiers", false, "Also sweep the stale workspace prefixes found with --older-than and the tagged resources of their identifiers")
+	flag.StringSliceVar(&options.StateDirs, "state-dir", nil, "State directory to sweep, glob patterns are expanded, may be repeated")
+	flag.StringSliceVar(&options.Buckets, "bucket", nil, "S3 backend bucket whose workspace prefixes are swept, may be repeated")
+	flag.StringSliceVar(&options.TagKeys, "tag-key", sweeper.DefaultTagKeys, "Tag searched for identifiers, may be repeated")
+	flag.StringVar(&options.Region, "region", os.Getenv("AWS_REGION"), "AWS region to sweep")
+	flag.BoolVar(&options.SkipTaggedResources, "skip-tagged-resources", false, "Do not sweep tagged AWS resources")
+	flag.BoolVar(&options.DryRun, "dry-run", false, "Report what would be swept without deleting anything")
+	flag.Parse()
+
+	report, err := sweeper.Sweep(context.Background(), options)
+	if report != nil {
+		printReport(os.Stdout, report, options.DryRun)
+	}
+	if err != nil {
+		log.Error("sweep failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// printReport lists what was deleted, or would be in a dry run, followed by what could not be deleted.
+func printReport(w io.Writer, report *sweeper.Report, dryRun bool) {
+	verb := "deleted"
+	if dryRun {
+		verb = "would delete"
+	}
+
+	failed := map[string]bool{}
+	for _, item := range report.Failed {
+		failed[item] = true
+	}
+	printDeleted := func(kind string, items []string) {
+		for _, item := range items {
+			if !failed[item] {
+				fmt.Fprintf(w, "%s %s %s\n", verb, kind, item)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "identifiers: %v\n", report.Identifiers)
+	if len(report.StaleIdentifiers) > 0 {
+		fmt.Fprintf(w, "stale identifiers: %v\n", report.StaleIdentifiers)
+	}
+	printDeleted("state file", report.StateFiles)
+	printDeleted("workspace prefix", report.S3Prefixes)
+	printDeleted("resource", report.Resources)
+	for _, resourceARN := range report.Skipped {
+		fmt.Fprintf(w, "skipped unsupported resource %s\n", resourceARN)
+	}
+	for _, item := range report.Failed {
+		fmt.Fprintf(w, "failed to delete %s\n", item)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/cloudposse/test-helpers/pkg/sweeper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintReportListsFailedDeletionsSeparately(t *testing.T) {
+	report := &sweeper.Report{
+		Identifiers: []string{"abc123"},
+		S3Prefixes:  []string{"s3://tfstate/abc123-test/"},
+		Resources:   []string{"arn:aws:s3:::eg-test-abc123", "arn:aws:ec2:us-east-2:111111111111:vpc/vpc-0123456789"},
+		Failed:      []string{"arn:aws:ec2:us-east-2:111111111111:vpc/vpc-0123456789"},
+		Errors:      []error{errors.New("deleting arn:aws:ec2:us-east-2:111111111111:vpc/vpc-0123456789: DependencyViolation")},
+	}
+
+	var out bytes.Buffer
+	printReport(&out, report, false)
+
+	assert.Equal(t, `identifiers: [abc123]
+deleted workspace prefix s3://tfstate/abc123-test/
+deleted resource arn:aws:s3:::eg-test-abc123
+failed to delete arn:aws:ec2:us-east-2:111111111111:vpc/vpc-0123456789
+`, out.String())
+}
@@ -1,16 +1,16 @@
 package atmos
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"regexp"
 	"strings"
+	"time"
 
 	tt "github.com/cloudposse/test-helpers/pkg/testing"
 	"github.com/gruntwork-io/terratest/modules/collections"
-	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/shell"
-	"github.com/gruntwork-io/terratest/modules/testing"
 )
 
 func generateCommand(options *Options, args ...string) shell.Command {
@@ -55,6 +55,18 @@ func GetCommonOptions(options *Options, args ...string) (*Options, []string) {
 		}
 		options.EnvVars["SSH_AUTH_SOCK"] = options.SshAgent.SocketFile()
 	}
+
+	// atmos runs terraform init on its own before most commands, so the plugin dir is passed to every init through the
+	// environment rather than as an argument.
+	if options.PluginDir != "" {
+		if options.EnvVars == nil {
+			options.EnvVars = map[string]string{}
+		}
+		pluginDirArg := fmt.Sprintf("-plugin-dir=%s", options.PluginDir)
+		if initArgs := options.EnvVars["TF_CLI_ARGS_init"]; !strings.Contains(initArgs, pluginDirArg) {
+			options.EnvVars["TF_CLI_ARGS_init"] = strings.TrimSpace(initArgs + " " + pluginDirArg)
+		}
+	}
 	return options, args
 }
 
@@ -69,44 +81,131 @@ func RunAtmosCommand(t tt.TestingT, additionalOptions *Options, args ...string)
 
 // RunAtmosCommandE runs atmos with the given arguments and options and return stdout/stderr.
 func RunAtmosCommandE(t tt.TestingT, additionalOptions *Options, additionalArgs ...string) (string, error) {
-	options, args := GetCommonOptions(additionalOptions, additionalArgs...)
-
-	cmd := generateCommand(options, args...)
-	description := fmt.Sprintf("%s %v", options.AtmosBinary, args)
-
-	return retry.DoWithRetryableErrorsE(t.(testing.TestingT), description, options.RetryableAtmosErrors, options.MaxRetries, options.TimeBetweenRetries, func() (string, error) {
-		s, err := shell.RunCommandAndGetOutputE(t.(testing.TestingT), cmd)
-		if err != nil {
-			return s, err
-		}
-
-		if err := hasWarning(additionalOptions, s); err != nil {
-			return s, err
-		}
-		return s, err
-	})
+	return RunAtmosCommandContextE(context.Background(), t, additionalOptions, additionalArgs...)
+}
 
+// RunAtmosCommandContextE runs atmos with the given arguments and options and return stdout/stderr. atmos and every
+// process it started are killed when ctx is done or a run of atmos takes longer than options.Timeout, in which case a
+// CommandTimedOut error is returned.
+func RunAtmosCommandContextE(ctx context.Context, t tt.TestingT, additionalOptions *Options, additionalArgs ...string) (string, error) {
+	result, err := RunAtmosCommandResultContextE(ctx, t, additionalOptions, additionalArgs...)
+	return result.Output, err
 }
 
 // RunAtmosCommandAndGetStdoutE runs atmos with the given arguments and options and returns solely its stdout (but not
 // stderr).
 func RunAtmosCommandAndGetStdoutE(t tt.TestingT, additionalOptions *Options, additionalArgs ...string) (string, error) {
+	result, err := RunAtmosCommandResultE(t, additionalOptions, additionalArgs...)
+	return result.Stdout, err
+}
+
+// CommandResult is the result of running an atmos command, including all of its retries.
+type CommandResult struct {
+	Stdout   string        // The stdout of the last attempt
+	Stderr   string        // The stderr of the last attempt
+	Output   string        // The stdout and stderr of the last attempt, in the order they were written
+	ExitCode int           // The exit code of the last attempt
+	Duration time.Duration // How long the command ran for, across all attempts
+	Attempts int           // How many times the command was run, including retries
+	Warnings []Warning     // The warnings terraform printed in the last attempt
+}
+
+// RunAtmosCommandResult runs atmos with the given arguments and options and returns its result. This will fail the
+// test if atmos fails.
+func RunAtmosCommandResult(t tt.TestingT, additionalOptions *Options, additionalArgs ...string) *CommandResult {
+	result, err := RunAtmosCommandResultE(t, additionalOptions, additionalArgs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// RunAtmosCommandResultE runs atmos with the given arguments and options and returns its result. The result is
+// returned even if atmos fails, so that its exit code and output can be inspected.
+func RunAtmosCommandResultE(t tt.TestingT, additionalOptions *Options, additionalArgs ...string) (*CommandResult, error) {
+	return RunAtmosCommandResultContextE(context.Background(), t, additionalOptions, additionalArgs...)
+}
+
+// RunAtmosCommandResultContextE runs atmos with the given arguments and options and returns its result, retrying the
+// errors of the retry policy of the options. Every attempt gets options.Timeout to finish. Once ctx is done, atmos is
+// killed and no further attempts are made.
+func RunAtmosCommandResultContextE(ctx context.Context, t tt.TestingT, additionalOptions *Options, additionalArgs ...string) (*CommandResult, error) {
 	options, args := GetCommonOptions(additionalOptions, additionalArgs...)
 
 	cmd := generateCommand(options, args...)
 	description := fmt.Sprintf("%s %v", options.AtmosBinary, args)
-	return retry.DoWithRetryableErrorsE(t, description, options.RetryableAtmosErrors, options.MaxRetries, options.TimeBetweenRetries, func() (string, error) {
-		s, err := shell.RunCommandAndGetStdOutE(t, cmd)
-		if err != nil {
-			return s, err
+	policy := retryPolicyFor(options)
+
+	startedAt := time.Now()
+	result := &CommandResult{}
+	retries := map[string]int{}
+	var lastErr, err error
+	for {
+		result.Attempts++
+		options.Logger.Logf(t, "%s", description)
+
+		var output CommandOutput
+		output, lastErr = runAtmosCommandAttempt(ctx, t, options, cmd)
+		result.Stdout, result.Stderr, result.Output = output.Stdout, output.Stderr, output.Combined
+
+		err = lastErr
+		if err == nil {
+			err = hasWarning(additionalOptions, output.Combined)
+		}
+		if err == nil || ctx.Err() != nil {
+			break
+		}
+
+		retryable, matchErr := policy.match(output.Combined, err)
+		if matchErr != nil {
+			err = matchErr
+			break
+		}
+		if retryable == nil {
+			break
 		}
 
-		if err := hasWarning(additionalOptions, s); err != nil {
-			return s, err
+		budget := policy.retryBudget(retryable)
+		wait, ok := policy.backoff(retryable, retries[budget], time.Since(startedAt))
+		if !ok {
+			err = RetriesExhausted{Args: cmd.Args, Retries: result.Attempts - 1, Underlying: err}
+			break
 		}
+		retries[budget]++
 
-		return s, err
-	})
+		options.Logger.Logf(t, "'%s' failed with the error '%s' but this error was expected and warrants a retry. Further details: %s", description, err, retryable.Message)
+		options.Logger.Logf(t, "Sleeping for %s and will try again.", wait)
+		if sleepErr := sleepContext(ctx, wait); sleepErr != nil {
+			err = CommandTimedOut{Args: cmd.Args, Cause: sleepErr}
+			break
+		}
+	}
+	result.Duration = time.Since(startedAt)
+	result.ExitCode, _ = exitCodeForError(lastErr)
+	result.Warnings = ParseWarnings(result.Output)
+	recordCommand(options, cmd, startedAt, result.Attempts, result.Output, lastErr, err)
+
+	return result, err
+}
+
+// runAtmosCommandAttempt runs atmos once, killing it when ctx is done or options.Timeout has passed.
+func runAtmosCommandAttempt(ctx context.Context, t tt.TestingT, options *Options, cmd shell.Command) (CommandOutput, error) {
+	attemptCtx := ctx
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	output, err := executorFor(options).Execute(attemptCtx, t, cmd)
+	if attemptCtx.Err() != nil {
+		timedOut := CommandTimedOut{Args: cmd.Args, Cause: attemptCtx.Err()}
+		if ctx.Err() == nil {
+			timedOut.Timeout = options.Timeout
+		}
+		return output, timedOut
+	}
+	return output, err
 }
 
 // GetExitCodeForAtmosCommand runs atmos with the given arguments and options and returns exit code
@@ -126,15 +225,10 @@ func GetExitCodeForAtmosCommandE(t tt.TestingT, additionalOptions *Options, addi
 	cmd := generateCommand(options, args...)
 	cmd.WorkingDir = options.AtmosBasePath
 
-	_, err := shell.RunCommandAndGetOutputE(t, cmd)
-	if err == nil {
-		return DefaultSuccessExitCode, nil
-	}
-	exitCode, getExitCodeErr := shell.GetExitCodeForRunCommandError(err)
-	if getExitCodeErr == nil {
-		return exitCode, nil
-	}
-	return DefaultErrorExitCode, getExitCodeErr
+	startedAt := time.Now()
+	output, err := runAtmosCommandAttempt(context.Background(), t, options, cmd)
+	recordCommand(options, cmd, startedAt, 1, output.Combined, err, err)
+	return exitCodeForError(err)
 }
 
 func defaultAtmosExecutable() string {
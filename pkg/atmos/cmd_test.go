@@ -0,0 +1,95 @@
+package atmos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunAtmosCommandResultE(t *testing.T) {
+	options := &Options{
+		Executor: NewFakeExecutor(
+			FakeResponse{Args: []string{"terraform", "init"}, Stdout: "Initializing", Stderr: "Error: registry service is unreachable", ExitCode: 1},
+			FakeResponse{Args: []string{"terraform", "init"}, Stdout: "Initialized", Stderr: "INFO done"},
+		),
+		RetryableAtmosErrors: map[string]string{".*registry service is unreachable.*": "transient"},
+		MaxRetries:           1,
+	}
+
+	result, err := RunAtmosCommandResultE(t, options, "terraform", "init", "vpc")
+	require.NoError(t, err)
+	assert.Equal(t, "Initialized", result.Stdout)
+	assert.Equal(t, "INFO done", result.Stderr)
+	assert.Equal(t, "Initialized\nINFO done", result.Output)
+	assert.Equal(t, DefaultSuccessExitCode, result.ExitCode)
+	assert.Equal(t, 2, result.Attempts)
+	assert.Positive(t, result.Duration)
+}
+
+func TestRunAtmosCommandResultEWithFailure(t *testing.T) {
+	options := &Options{Executor: NewFakeExecutor(FakeResponse{Stderr: "Error: invalid stack", ExitCode: 3})}
+
+	result, err := RunAtmosCommandResultE(t, options, "terraform", "plan", "vpc")
+	require.Error(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, 3, result.ExitCode)
+	assert.Equal(t, "Error: invalid stack", result.Stderr)
+	assert.Equal(t, 1, result.Attempts)
+}
+
+func TestOutputEParsesOnlyStdout(t *testing.T) {
+	options := &Options{
+		Component: "vpc",
+		Stack:     "default-test",
+		Executor: NewFakeExecutor(FakeResponse{
+			Stdout: "Switched to workspace \"default-test\".\n\"INFO: vpc-123\"",
+			Stderr: "{\"not\": \"the output\"}",
+		}),
+	}
+
+	out, err := OutputE(t, options, "vpc_id")
+	require.NoError(t, err)
+	assert.Equal(t, "INFO: vpc-123", out)
+}
+
+func TestCleanOutput(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want string
+	}{
+		{name: "document", out: `{"a": 1}`, want: `{"a": 1}`},
+		{name: "value containing INFO", out: "{\n  \"log\": \"INFO started\"\n}\n", want: "{\n  \"log\": \"INFO started\"\n}"},
+		{name: "workspace switch", out: "Switched to workspace \"test\".\n[\"a\"]", want: `["a"]`},
+		{name: "log line starting with a date", out: "2025-01-01 INFO output\ntrue", want: "true"},
+		{name: "debug lines after document", out: "\"value\"\n::debug::done", want: `"value"`},
+		{name: "escape sequences", out: "\x1b[0m{\"a\": \x1b[1m1\x1b[0m}", want: `{"a": 1}`},
+		{name: "unicode value", out: `{"name": "données"}`, want: `{"name": "données"}`},
+		{name: "no document", out: " Error: nothing to output \n", want: "Error: nothing to output"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, cleanOutput(tc.out))
+		})
+	}
+}
+
+func TestRunAtmosCommandEPassesPluginDirToInit(t *testing.T) {
+	executor := NewFakeExecutor(FakeResponse{})
+	options := &Options{
+		PluginDir: "/providers",
+		EnvVars:   map[string]string{"TF_CLI_ARGS_init": "-upgrade"},
+		Executor:  executor,
+	}
+
+	_, err := RunAtmosCommandE(t, options, "terraform", "plan", "vpc")
+	require.NoError(t, err)
+	_, err = RunAtmosCommandE(t, options, "terraform", "apply", "vpc")
+	require.NoError(t, err)
+
+	call, ok := executor.LastCall()
+	require.True(t, ok)
+	assert.Equal(t, "-upgrade -plugin-dir=/providers", call.Env["TF_CLI_ARGS_init"])
+}
@@ -6,6 +6,7 @@ import (
 	log "github.com/charmbracelet/log"
 	"github.com/cloudposse/test-helpers/pkg/atmos"
 	c "github.com/cloudposse/test-helpers/pkg/atmos/component-helper/config"
+	"github.com/cloudposse/test-helpers/pkg/atmos/component-helper/state"
 	"github.com/stretchr/testify/require"
 )
 
@@ -24,14 +25,44 @@ func (s *TestSuite) DeployDependencies(t *testing.T, config *c.Config) {
 		return
 	}
 
-	for _, dependency := range s.Dependencies {
+	// Options are resolved up front because resolving them may fail the test, which must happen on the test goroutine.
+	atmosOptions := make([]*atmos.Options, len(s.Dependencies))
+	for i, dependency := range s.Dependencies {
+		atmosOptions[i] = getAtmosOptions(t, config, dependency.ComponentName, dependency.StackName, dependency.AdditionalVars)
+		atmosOptions[i].MergeOptions(dependency.Options)
+		s.engine.AddDiagnosticsTarget(atmosOptions[i])
+	}
+
+	// Every dependency is deployed in its own subtest, so that a failure is reported against the dependency.
+	err := s.engine.WalkDependencies(t, phaseName, s.Dependencies, config.DependencyParallelism, false, func(t *testing.T, i int) error {
+		dependency := s.Dependencies[i]
+		if s.State == nil || !dependency.IsComponent() {
+			return s.engine.DeployDependency(t, dependency, atmosOptions[i])
+		}
+
+		if s.State.Applied(dependency.ComponentName, dependency.StackName) {
+			log.Info("skipping dependency already deployed by a previous run", "component", dependency.ComponentName, "stack", dependency.StackName)
+			t.Skip("already deployed by a previous run")
+		}
+
+		if err := s.State.SetApplyStatus(dependency.ComponentName, dependency.StackName, state.StatusStarted); err != nil {
+			return err
+		}
+
 		log.Info("deploying dependency", "component", dependency.ComponentName, "stack", dependency.StackName)
-		atmosOptions := getAtmosOptions(t, config, dependency.ComponentName, dependency.StackName, dependency.AdditionalVars)
-		_, err := atmos.ApplyE(t, atmosOptions)
-		if err != nil {
-			s.logPhaseStatus(phaseName, "failed")
-			require.NoError(t, err)
+		_, applyErr := atmos.ApplyE(t, atmosOptions[i])
+		status := state.StatusCompleted
+		if applyErr != nil {
+			status = state.StatusFailed
+		}
+		if err := s.State.SetApplyStatus(dependency.ComponentName, dependency.StackName, status); err != nil && applyErr == nil {
+			return err
 		}
+		return applyErr
+	})
+	if err != nil {
+		s.logPhaseStatus(phaseName, "failed")
+		require.NoError(t, err)
 	}
 	s.logPhaseStatus(phaseName, "completed")
 }
@@ -0,0 +1,30 @@
+package component_helper
+
+import (
+	"testing"
+
+	log "github.com/charmbracelet/log"
+	"github.com/cloudposse/test-helpers/pkg/atmos"
+	c "github.com/cloudposse/test-helpers/pkg/atmos/component-helper/config"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TestSuite) ValidateStacks(t *testing.T, config *c.Config) {
+	const phaseName = "setup/validate stacks"
+	if !config.ValidateStacks {
+		s.logPhaseStatus(phaseName, "skipped")
+		return
+	}
+
+	s.logPhaseStatus(phaseName, "started")
+
+	log.Debug("running atmos validate stacks in tempdir")
+	atmosOptions := getAtmosOptions(t, config, "", "", nil)
+	_, err := atmos.ValidateStacksE(t, atmosOptions)
+	if err != nil {
+		s.logPhaseStatus(phaseName, "failed")
+		require.NoError(t, err)
+	}
+
+	s.logPhaseStatus(phaseName, "completed")
+}
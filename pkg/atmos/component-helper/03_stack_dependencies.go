@@ -0,0 +1,250 @@
+package component_helper
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"testing"
+
+	log "github.com/charmbracelet/log"
+	"github.com/cloudposse/test-helpers/pkg/atmos"
+	c "github.com/cloudposse/test-helpers/pkg/atmos/component-helper/config"
+	"github.com/cloudposse/test-helpers/pkg/atmos/component-helper/dependency"
+	"github.com/stretchr/testify/require"
+)
+
+type stackDependencyRoot struct {
+	ComponentName string
+	StackName     string
+}
+
+// dependsOnEntry is a single entry of the settings.depends_on section of an atmos component.
+type dependsOnEntry struct {
+	ComponentName string
+	StackName     string            // The stack of the dependency, empty until Context has been resolved
+	Context       map[string]string // The context fields the entry overrides, such as tenant or stage
+}
+
+// dependsOnContextFields are the context fields a settings.depends_on entry can set instead of a stack, to refer to the
+// component in the stack those fields resolve to.
+var dependsOnContextFields = []string{"namespace", "tenant", "environment", "stage"}
+
+// AddDependenciesFromStack registers every component that componentName declares in its settings.depends_on section in
+// stackName, and transitively their own dependencies, as dependencies of the test suite. The chain is resolved with
+// atmos describe component during setup, once the fixtures have been copied to the temp dir.
+func (s *TestSuite) AddDependenciesFromStack(componentName string, stackName string) {
+	s.stackDependencyRoots = append(s.stackDependencyRoots, stackDependencyRoot{
+		ComponentName: componentName,
+		StackName:     stackName,
+	})
+}
+
+func (s *TestSuite) ResolveStackDependencies(t *testing.T, config *c.Config) {
+	const phaseName = "setup/resolve stack dependencies"
+	if len(s.stackDependencyRoots) == 0 {
+		return
+	}
+
+	s.logPhaseStatus(phaseName, "started")
+
+	resolved := map[string]bool{}
+	stacks := &stackIndex{}
+	for _, root := range s.stackDependencyRoots {
+		err := s.addStackDependencies(t, config, root.ComponentName, root.StackName, true, map[string]bool{}, resolved, stacks)
+		if err != nil {
+			s.logPhaseStatus(phaseName, "failed")
+			require.NoError(t, err)
+		}
+	}
+
+	s.logPhaseStatus(phaseName, "completed")
+}
+
+// addStackDependencies walks the settings.depends_on chain of the component depth first, registering the dependencies
+// of a component before the component itself so that the slice order is a valid deployment order. The root component is
+// the component under test and is not registered.
+func (s *TestSuite) addStackDependencies(t *testing.T, config *c.Config, componentName string, stackName string, root bool, visiting map[string]bool, resolved map[string]bool, stacks *stackIndex) error {
+	key := fmt.Sprintf("%s/%s", stackName, componentName)
+	if visiting[key] {
+		return fmt.Errorf("component %q in stack %q has a cyclic settings.depends_on chain", componentName, stackName)
+	}
+	if resolved[key] {
+		return nil
+	}
+	visiting[key] = true
+	defer delete(visiting, key)
+
+	atmosOptions := getAtmosOptions(t, config, componentName, stackName, nil)
+	component, err := atmos.DescribeComponentE(t, atmosOptions)
+	if err != nil {
+		return err
+	}
+
+	var dependsOn []dependency.ComponentRef
+	for _, entry := range parseDependsOn(component.Settings, stackName) {
+		if entry.StackName == "" {
+			entry.StackName, err = stacks.stackFor(t, config, entry, component.Vars)
+			if err != nil {
+				return fmt.Errorf("resolving settings.depends_on of component %q in stack %q: %w", componentName, stackName, err)
+			}
+		}
+
+		if err := s.addStackDependencies(t, config, entry.ComponentName, entry.StackName, false, visiting, resolved, stacks); err != nil {
+			return err
+		}
+		dependsOn = append(dependsOn, dependency.ComponentRef{ComponentName: entry.ComponentName, StackName: entry.StackName})
+	}
+
+	resolved[key] = true
+	if !root {
+		log.WithPrefix(t.Name()).Info("adding dependency from stack", "component", componentName, "stack", stackName, "dependsOn", dependsOn)
+		s.addOrUpdateDependency(componentName, stackName, dependsOn)
+	}
+
+	return nil
+}
+
+// addOrUpdateDependency registers the component as a dependency, or adds the given edges to it if it was already
+// registered, e.g. by AddDependency. The edges refer to the components in their stacks, so that the same component in
+// another stack is not depended on.
+func (s *TestSuite) addOrUpdateDependency(componentName string, stackName string, dependsOn []dependency.ComponentRef) {
+	for _, d := range s.Dependencies {
+		if d.ComponentName == componentName && d.StackName == stackName {
+			for _, ref := range dependsOn {
+				if !slices.Contains(d.DependsOnComponents, ref) {
+					d.DependsOnComponents = append(d.DependsOnComponents, ref)
+				}
+			}
+			return
+		}
+	}
+
+	s.Dependencies = append(s.Dependencies, &dependency.Dependency{
+		ComponentName:       componentName,
+		StackName:           stackName,
+		DependsOnComponents: dependsOn,
+	})
+}
+
+// parseDependsOn extracts the component dependencies from the settings section returned by atmos describe component.
+// settings.depends_on is a map keyed by an (usually numeric) index, or a list. Entries with neither a stack nor context
+// fields refer to the current stack. Entries that only set context fields, such as tenant or stage, are returned without
+// a stack and with those fields in Context, to be resolved by stackIndex. Entries that only declare files or folders are
+// ignored.
+func parseDependsOn(settings interface{}, stackName string) []dependsOnEntry {
+	settingsMap, ok := settings.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var rawEntries []interface{}
+	switch dependsOn := settingsMap["depends_on"].(type) {
+	case []interface{}:
+		rawEntries = dependsOn
+	case map[string]interface{}:
+		keys := make([]string, 0, len(dependsOn))
+		for k := range dependsOn {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			a, errA := strconv.Atoi(keys[i])
+			b, errB := strconv.Atoi(keys[j])
+			if errA == nil && errB == nil {
+				return a < b
+			}
+			return keys[i] < keys[j]
+		})
+		for _, k := range keys {
+			rawEntries = append(rawEntries, dependsOn[k])
+		}
+	}
+
+	var entries []dependsOnEntry
+	for _, raw := range rawEntries {
+		entry, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		componentName, _ := entry["component"].(string)
+		if componentName == "" {
+			continue
+		}
+		entryStack, _ := entry["stack"].(string)
+		var context map[string]string
+		if entryStack == "" {
+			for _, field := range dependsOnContextFields {
+				if value, ok := entry[field].(string); ok && value != "" {
+					if context == nil {
+						context = map[string]string{}
+					}
+					context[field] = value
+				}
+			}
+			if context == nil {
+				entryStack = stackName
+			}
+		}
+		entries = append(entries, dependsOnEntry{ComponentName: componentName, StackName: entryStack, Context: context})
+	}
+
+	return entries
+}
+
+// stackIndex resolves the context fields of settings.depends_on entries into stack names. The stacks are described once,
+// when the first entry that needs it is resolved.
+type stackIndex struct {
+	stacks *atmos.DescribeStacksOutput
+}
+
+// stackFor returns the stack of the dependency declared by entry, in the context of a component with the given vars.
+func (i *stackIndex) stackFor(t *testing.T, config *c.Config, entry dependsOnEntry, vars interface{}) (string, error) {
+	if i.stacks == nil {
+		stacks, err := atmos.DescribeStacksE(t, getAtmosOptions(t, config, "", "", nil))
+		if err != nil {
+			return "", err
+		}
+		i.stacks = stacks
+	}
+	return stackNameForContext(i.stacks, entry, vars)
+}
+
+// stackNameForContext returns the only stack that defines the component of entry with the context fields of vars,
+// overridden by the context fields of entry. It fails if no stack, or more than one, matches.
+func stackNameForContext(stacks *atmos.DescribeStacksOutput, entry dependsOnEntry, vars interface{}) (string, error) {
+	context := map[string]string{}
+	varsMap, _ := vars.(map[string]interface{})
+	for _, field := range dependsOnContextFields {
+		if value, ok := varsMap[field].(string); ok && value != "" {
+			context[field] = value
+		}
+	}
+	for field, value := range entry.Context {
+		context[field] = value
+	}
+
+	var matches []string
+	for stackName, stack := range stacks.Stacks {
+		component, ok := stack.Components.Terraform[entry.ComponentName]
+		if !ok {
+			continue
+		}
+		componentVars, _ := component.Vars.(map[string]interface{})
+		matching := true
+		for field, value := range context {
+			if componentVars[field] != value {
+				matching = false
+				break
+			}
+		}
+		if matching {
+			matches = append(matches, stackName)
+		}
+	}
+	sort.Strings(matches)
+
+	if len(matches) != 1 {
+		return "", fmt.Errorf("expected exactly one stack with component %q and context %v but found %d: %v", entry.ComponentName, context, len(matches), matches)
+	}
+	return matches[0], nil
+}
@@ -0,0 +1,99 @@
+package component_helper
+
+import (
+	"testing"
+
+	"github.com/cloudposse/test-helpers/pkg/atmos"
+	"github.com/cloudposse/test-helpers/pkg/atmos/component-helper/dependency"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDependsOnSortsMapEntriesNumerically(t *testing.T) {
+	settings := map[string]interface{}{
+		"depends_on": map[string]interface{}{
+			"10": map[string]interface{}{"component": "eks/cluster"},
+			"2":  map[string]interface{}{"component": "vpc", "stack": "test-use2-network"},
+			"1":  map[string]interface{}{"component": "dns-delegated"},
+			"3":  map[string]interface{}{"file": "configs/foo.json"},
+		},
+	}
+
+	entries := parseDependsOn(settings, "test-use2-sandbox")
+	assert.Equal(t, []dependsOnEntry{
+		{ComponentName: "dns-delegated", StackName: "test-use2-sandbox"},
+		{ComponentName: "vpc", StackName: "test-use2-network"},
+		{ComponentName: "eks/cluster", StackName: "test-use2-sandbox"},
+	}, entries)
+}
+
+func TestParseDependsOnSupportsLists(t *testing.T) {
+	settings := map[string]interface{}{
+		"depends_on": []interface{}{
+			map[string]interface{}{"component": "vpc"},
+		},
+	}
+
+	entries := parseDependsOn(settings, "test-use2-sandbox")
+	assert.Equal(t, []dependsOnEntry{{ComponentName: "vpc", StackName: "test-use2-sandbox"}}, entries)
+}
+
+func TestParseDependsOnKeepsContextFieldsToResolve(t *testing.T) {
+	settings := map[string]interface{}{
+		"depends_on": []interface{}{
+			map[string]interface{}{"component": "vpc", "stage": "network"},
+			map[string]interface{}{"component": "dns", "stage": "network", "stack": "test-use2-dns"},
+		},
+	}
+
+	entries := parseDependsOn(settings, "test-use2-sandbox")
+	assert.Equal(t, []dependsOnEntry{
+		{ComponentName: "vpc", Context: map[string]string{"stage": "network"}},
+		{ComponentName: "dns", StackName: "test-use2-dns"},
+	}, entries)
+}
+
+func TestStackNameForContext(t *testing.T) {
+	component := func(vars map[string]interface{}) atmos.DescribeStacksComponent {
+		return atmos.DescribeStacksComponent{Components: atmos.DescribeStacksTerraform{Terraform: map[string]atmos.DescribeStacksTerraformComponent{
+			"vpc": {Vars: vars},
+		}}}
+	}
+	stacks := &atmos.DescribeStacksOutput{Stacks: map[string]atmos.DescribeStacksComponent{
+		"test-use2-sandbox": component(map[string]interface{}{"tenant": "test", "environment": "use2", "stage": "sandbox"}),
+		"test-use2-network": component(map[string]interface{}{"tenant": "test", "environment": "use2", "stage": "network"}),
+		"test-usw2-network": component(map[string]interface{}{"tenant": "test", "environment": "usw2", "stage": "network"}),
+	}}
+	vars := map[string]interface{}{"tenant": "test", "environment": "use2", "stage": "sandbox", "enabled": true}
+
+	stackName, err := stackNameForContext(stacks, dependsOnEntry{ComponentName: "vpc", Context: map[string]string{"stage": "network"}}, vars)
+	require.NoError(t, err)
+	assert.Equal(t, "test-use2-network", stackName)
+
+	_, err = stackNameForContext(stacks, dependsOnEntry{ComponentName: "vpc", Context: map[string]string{"stage": "prod"}}, vars)
+	assert.ErrorContains(t, err, "found 0")
+
+	_, err = stackNameForContext(stacks, dependsOnEntry{ComponentName: "vpc", Context: map[string]string{"stage": "network"}}, map[string]interface{}{"tenant": "test"})
+	assert.ErrorContains(t, err, "found 2")
+}
+
+func TestParseDependsOnWithoutSettings(t *testing.T) {
+	assert.Empty(t, parseDependsOn(nil, "test-use2-sandbox"))
+	assert.Empty(t, parseDependsOn(map[string]interface{}{}, "test-use2-sandbox"))
+}
+
+func TestAddOrUpdateDependencyMergesEdges(t *testing.T) {
+	s := NewTestSuite()
+	s.AddDependency(t, "eks/cluster", "test-use2-sandbox", nil, "vpc")
+
+	vpc := dependency.ComponentRef{ComponentName: "vpc", StackName: "test-use2-sandbox"}
+	dns := dependency.ComponentRef{ComponentName: "dns-delegated", StackName: "gbl-dns"}
+	s.addOrUpdateDependency("eks/cluster", "test-use2-sandbox", []dependency.ComponentRef{vpc, dns})
+	s.addOrUpdateDependency("eks/cluster", "test-use2-sandbox", []dependency.ComponentRef{vpc})
+	s.addOrUpdateDependency("vpc", "test-use2-sandbox", nil)
+
+	assert.Len(t, s.Dependencies, 2)
+	assert.Equal(t, []string{"vpc"}, s.Dependencies[0].DependsOn)
+	assert.Equal(t, []dependency.ComponentRef{vpc, dns}, s.Dependencies[0].DependsOnComponents)
+	assert.Equal(t, "vpc", s.Dependencies[1].ComponentName)
+}
@@ -35,6 +35,12 @@ func (s *TestSuite) VendorDependencies(t *testing.T, config *c.Config) {
 		return
 	}
 
+	if s.State != nil && s.State.PhaseCompleted(phaseName) {
+		log.WithPrefix(t.Name()).Info("dependencies were already vendored into the temp dir by a previous run", "phase", phaseName)
+		s.logPhaseStatus(phaseName, "skipped")
+		return
+	}
+
 	s.logPhaseStatus(phaseName, "started")
 
 	log.Debug("running atmos vendor pull in tempdir")
@@ -0,0 +1,220 @@
+package component_helper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	log "github.com/charmbracelet/log"
+	"github.com/cloudposse/test-helpers/pkg/atmos"
+	"github.com/cloudposse/test-helpers/pkg/atmos/lifecycle"
+	"github.com/gruntwork-io/terratest/modules/collections"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// upgradeVendorFile is the vendor manifest written to the temp dir to pull the released version of the component.
+const upgradeVendorFile = "vendor.upgrade.yaml"
+
+// UpgradeTest proves that the working copy of the component can be applied over its last released version. It vendors
+// the release from Config.UpgradeSource into the component directory, deploys it, swaps the working copy from
+// Config.SrcDir back in and plans. The test fails if the plan deletes or replaces any resource, except for the resource
+// addresses listed in allowedDestroys. The component is destroyed when the test ends.
+func (s *TestSuite) UpgradeTest(componentName, stackName string, additionalVars *map[string]interface{}, allowedDestroys ...string) {
+	const phaseName = "upgrade test"
+	t := s.T()
+
+	if s.Config.SkipUpgradeTest {
+		s.logPhaseStatus(phaseName, "skipped")
+		t.Skip()
+	}
+
+	require.NotEmpty(t, s.Config.UpgradeSource, "the upgrade test requires UpgradeSource to be set")
+
+	s.logPhaseStatus(phaseName, "started")
+
+	releaseVersion := s.Config.UpgradeFromVersion
+	if releaseVersion == "" {
+		var err error
+		releaseVersion, err = latestReleaseVersionE(t, s.Config.UpgradeSource)
+		if err != nil {
+			s.logPhaseStatus(phaseName, "failed")
+			require.NoError(t, err)
+		}
+	}
+
+	destPath := componentDestPath(s.Config)
+	swapped := false
+	defer func() {
+		// Never leave the released version behind for the tests that run after this one.
+		if !swapped {
+			s.swapInWorkingCopy(t, destPath)
+		}
+	}()
+
+	log.WithPrefix(t.Name()).Info("deploying released version of the component", "component", componentName, "version", releaseVersion)
+	s.vendorRelease(t, destPath, releaseVersion)
+
+	defer s.DestroyAtmosComponent(t, componentName, stackName, additionalVars)
+
+	mergedVars := s.getMergedVars(t, additionalVars)
+	atmosOptions := getAtmosOptions(t, s.Config, componentName, stackName, &mergedVars)
+	if _, err := atmos.ApplyE(t, atmosOptions); err != nil {
+		s.logPhaseStatus(phaseName, "failed")
+		require.NoError(t, err)
+	}
+
+	log.WithPrefix(t.Name()).Info("planning upgrade to the working copy of the component", "component", componentName, "srcDir", s.Config.SrcDir)
+	s.swapInWorkingCopy(t, destPath)
+	swapped = true
+
+	atmosOptions.PlanFilePath = filepath.Join(t.TempDir(), "upgrade.planfile")
+	plan, err := atmos.PlanAndShowWithStructE(t, atmosOptions)
+	if err != nil {
+		s.logPhaseStatus(phaseName, "failed")
+		require.NoError(t, err)
+	}
+
+	if err := checkUpgradePlan(t, plan, allowedDestroys); err != nil {
+		s.logPhaseStatus(phaseName, "failed")
+		require.NoError(t, err, "upgrading from version %s", releaseVersion)
+	}
+
+	s.logPhaseStatus(phaseName, "completed")
+}
+
+// vendorRelease replaces the component directory with the released version of the component by running atmos vendor
+// pull against a vendor manifest that only contains the component, pinned to releaseVersion.
+func (s *TestSuite) vendorRelease(t *testing.T, destPath string, releaseVersion string) {
+	target, err := filepath.Rel(s.Config.TempDir, destPath)
+	require.NoError(t, err)
+
+	manifest := map[string]interface{}{
+		"apiVersion": "atmos/v1",
+		"kind":       "AtmosVendorConfig",
+		"metadata": map[string]interface{}{
+			"name":        "upgrade-test",
+			"description": "Released version of the component under test",
+		},
+		"spec": map[string]interface{}{
+			"sources": []map[string]interface{}{{
+				"component": filepath.Base(destPath),
+				"source":    s.Config.UpgradeSource,
+				"version":   releaseVersion,
+				"targets":   []string{filepath.ToSlash(target)},
+			}},
+		},
+	}
+	content, err := yaml.Marshal(manifest)
+	require.NoError(t, err)
+
+	vendorFile := filepath.Join(s.Config.TempDir, upgradeVendorFile)
+	err = os.WriteFile(vendorFile, content, 0644)
+	require.NoError(t, err)
+
+	err = os.RemoveAll(destPath)
+	require.NoError(t, err)
+
+	atmosOptions := getAtmosOptions(t, s.Config, "", "", nil)
+	atmosOptions.EnvVars["ATMOS_VENDOR_BASE_PATH"] = vendorFile
+	_, err = atmos.VendorPullE(t, atmosOptions)
+	require.NoError(t, err)
+
+	s.restoreLocalBackend(t, destPath)
+}
+
+// swapInWorkingCopy replaces the component directory with the working copy of the component from Config.SrcDir.
+func (s *TestSuite) swapInWorkingCopy(t *testing.T, destPath string) {
+	err := os.RemoveAll(destPath)
+	require.NoError(t, err)
+
+	err = lifecycle.CopyDirectoryContents(s.Config.SrcDir, destPath)
+	require.NoError(t, err)
+
+	s.restoreLocalBackend(t, destPath)
+}
+
+// checkUpgradePlan fails if the plan deletes or replaces resources that are not explicitly allowed to be.
+func checkUpgradePlan(t *testing.T, plan *atmos.PlanStruct, allowedDestroys []string) error {
+	err := atmos.AssertNoDestroysE(t, plan)
+
+	var unexpected atmos.PlanHasUnexpectedChanges
+	if !errors.As(err, &unexpected) {
+		return err
+	}
+
+	for address := range unexpected.Changes {
+		if collections.ListContains(allowedDestroys, address) {
+			delete(unexpected.Changes, address)
+		}
+	}
+	if len(unexpected.Changes) == 0 {
+		return nil
+	}
+	return unexpected
+}
+
+// latestReleaseVersionE returns the highest release tag of the git repository the vendor source points to. Pre-release
+// tags are ignored.
+func latestReleaseVersionE(t *testing.T, source string) (string, error) {
+	repository := sourceRepository(source)
+	out, err := shell.RunCommandAndGetStdOutE(t, shell.Command{
+		Command: "git",
+		Args:    []string{"ls-remote", "--tags", "--refs", repository},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	tag, err := latestVersionTag(out)
+	if err != nil {
+		return "", fmt.Errorf("finding the latest release of %s: %w", repository, err)
+	}
+	return tag, nil
+}
+
+// sourceRepository returns the git repository URL of a vendor source such as
+// github.com/cloudposse-terraform-components/aws-vpc.git//src?ref={{.Version}}.
+func sourceRepository(source string) string {
+	repository, _, _ := strings.Cut(source, "?")
+	repository = strings.TrimPrefix(repository, "git::")
+
+	scheme := "https://"
+	if i := strings.Index(repository, "://"); i >= 0 {
+		scheme, repository = repository[:i+3], repository[i+3:]
+	}
+	repository, _, _ = strings.Cut(repository, "//")
+
+	return scheme + repository
+}
+
+// latestVersionTag returns the highest version tag in the output of git ls-remote --tags, as it is spelled in the tag.
+func latestVersionTag(lsRemoteOutput string) (string, error) {
+	var latestTag string
+	var latest *version.Version
+	for _, line := range strings.Split(lsRemoteOutput, "\n") {
+		_, ref, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+		tag := strings.TrimPrefix(ref, "refs/tags/")
+
+		v, err := version.NewVersion(tag)
+		if err != nil || v.Prerelease() != "" {
+			continue
+		}
+		if latest == nil || v.GreaterThan(latest) {
+			latest, latestTag = v, tag
+		}
+	}
+
+	if latest == nil {
+		return "", errors.New("no release tags found")
+	}
+	return latestTag, nil
+}
@@ -0,0 +1,51 @@
+package component_helper
+
+import (
+	"testing"
+
+	"github.com/cloudposse/test-helpers/pkg/atmos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceRepository(t *testing.T) {
+	assert.Equal(t, "https://github.com/cloudposse-terraform-components/aws-vpc.git",
+		sourceRepository("github.com/cloudposse-terraform-components/aws-vpc.git//src?ref={{.Version}}"))
+	assert.Equal(t, "https://github.com/cloudposse/terraform-aws-components.git",
+		sourceRepository("git::https://github.com/cloudposse/terraform-aws-components.git//modules/vpc?ref={{.Version}}"))
+	assert.Equal(t, "ssh://git@github.com/acme/components.git",
+		sourceRepository("ssh://git@github.com/acme/components.git?ref={{.Version}}"))
+}
+
+func TestLatestVersionTag(t *testing.T) {
+	out := "1111111\trefs/tags/v1.9.0\n" +
+		"2222222\trefs/tags/v1.10.0\n" +
+		"3333333\trefs/tags/v1.11.0-rc1\n" +
+		"4444444\trefs/tags/not-a-version\n"
+
+	tag, err := latestVersionTag(out)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.10.0", tag)
+
+	_, err = latestVersionTag("")
+	assert.Error(t, err)
+}
+
+func TestCheckUpgradePlan(t *testing.T) {
+	plan, err := atmos.ParsePlanJSON(`{
+  "format_version": "1.2",
+  "resource_changes": [
+    {"address": "aws_s3_bucket.default", "change": {"actions": ["update"]}},
+    {"address": "aws_iam_role.default", "change": {"actions": ["delete", "create"]}},
+    {"address": "aws_iam_policy.default", "change": {"actions": ["delete"]}}
+  ]
+}`)
+	require.NoError(t, err)
+
+	err = checkUpgradePlan(t, plan, []string{"aws_iam_policy.default"})
+	var unexpected atmos.PlanHasUnexpectedChanges
+	require.ErrorAs(t, err, &unexpected)
+	assert.Equal(t, map[string][]string{"aws_iam_role.default": {"delete", "create"}}, unexpected.Changes)
+
+	assert.NoError(t, checkUpgradePlan(t, plan, []string{"aws_iam_policy.default", "aws_iam_role.default"}))
+}
@@ -1,32 +1,27 @@
 package component_helper
 
 import (
-	"os"
 	"testing"
 
 	log "github.com/charmbracelet/log"
 	"github.com/cloudposse/test-helpers/pkg/atmos"
 	c "github.com/cloudposse/test-helpers/pkg/atmos/component-helper/config"
+	"github.com/cloudposse/test-helpers/pkg/atmos/component-helper/state"
 	"github.com/stretchr/testify/require"
 )
 
 func (s *TestSuite) DestroyConfigFile(t *testing.T, config *c.Config) {
-	const phaseName = "teardown/destroy config file"
-
-	if anyPhasesSkipped(config) {
-		s.logPhaseStatus(phaseName, "skipped")
+	s.engine.DestroyConfigFile(t, config)
+	if config.AnyPhasesSkipped() {
 		return
 	}
 
-	s.logPhaseStatus(phaseName, "started")
-
-	err := os.Remove(config.ConfigFilePath)
-	if err != nil {
-		s.logPhaseStatus(phaseName, "failed")
+	// The state ledger is removed last, since recording the phase status above rewrites it.
+	if s.State != nil {
+		err := s.State.Remove()
 		require.NoError(t, err)
+		s.State = nil
 	}
-
-	s.logPhaseStatus(phaseName, "completed")
 }
 
 func (s *TestSuite) DestroyDependencies(t *testing.T, config *c.Config) {
@@ -45,37 +40,48 @@ func (s *TestSuite) DestroyDependencies(t *testing.T, config *c.Config) {
 		return
 	}
 
-	for i := len(s.Dependencies) - 1; i >= 0; i-- {
+	atmosOptions := make([]*atmos.Options, len(s.Dependencies))
+	for i, dependency := range s.Dependencies {
+		atmosOptions[i] = getAtmosOptions(t, config, dependency.ComponentName, dependency.StackName, dependency.AdditionalVars)
+		atmosOptions[i].MergeOptions(dependency.Options)
+	}
+
+	// Dependencies are destroyed in reverse topological order, so nothing is destroyed while a dependent still exists.
+	// Every dependency is destroyed in its own subtest, so that a failure is reported against the dependency.
+	err := s.engine.WalkDependencies(t, phaseName, s.Dependencies, config.DependencyParallelism, true, func(t *testing.T, i int) error {
 		dependency := s.Dependencies[i]
+		if s.State == nil || !dependency.IsComponent() {
+			return s.engine.DestroyDependency(t, dependency, atmosOptions[i])
+		}
+
+		if !s.State.NeedsDestroy(dependency.ComponentName, dependency.StackName) {
+			log.Info("skipping dependency that was never deployed or is already destroyed", "component", dependency.ComponentName, "stack", dependency.StackName)
+			t.Skip("never deployed or already destroyed")
+		}
+
+		if err := s.State.SetDestroyStatus(dependency.ComponentName, dependency.StackName, state.StatusStarted); err != nil {
+			return err
+		}
+
 		log.Info("destroying dependency", "component", dependency.ComponentName, "stack", dependency.StackName)
-		atmosOptions := getAtmosOptions(t, config, dependency.ComponentName, dependency.StackName, dependency.AdditionalVars)
-		_, err := atmos.DestroyE(t, atmosOptions)
-		if err != nil {
-			s.logPhaseStatus(phaseName, "failed")
-			require.NoError(t, err)
+		_, destroyErr := atmos.DestroyE(t, atmosOptions[i])
+		status := state.StatusCompleted
+		if destroyErr != nil {
+			status = state.StatusFailed
+		}
+		if err := s.State.SetDestroyStatus(dependency.ComponentName, dependency.StackName, status); err != nil && destroyErr == nil {
+			return err
 		}
+		return destroyErr
+	})
+	if err != nil {
+		s.logPhaseStatus(phaseName, "failed")
+		require.NoError(t, err)
 	}
 
 	s.logPhaseStatus(phaseName, "completed")
 }
 
 func (s *TestSuite) DestroyTempDir(t *testing.T, config *c.Config) {
-	const phaseName = "teardown/destroy temp dir"
-
-	if anyPhasesSkipped(config) {
-		s.logPhaseStatus(phaseName, "skipped")
-		return
-	}
-
-	s.logPhaseStatus(phaseName, "started")
-
-	log.WithPrefix(t.Name()).Info("removing terraform state directory", "path", config.StateDir)
-	err := os.RemoveAll(config.StateDir)
-	require.NoError(t, err)
-
-	log.WithPrefix(t.Name()).Info("removing temp directory", "path", config.TempDir)
-	err = os.RemoveAll(config.TempDir)
-	require.NoError(t, err)
-
-	s.logPhaseStatus(phaseName, "completed")
+	s.engine.DestroyTempDir(t, config)
 }
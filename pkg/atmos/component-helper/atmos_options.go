@@ -7,6 +7,7 @@ import (
 	"dario.cat/mergo"
 	"github.com/cloudposse/test-helpers/pkg/atmos"
 	c "github.com/cloudposse/test-helpers/pkg/atmos/component-helper/config"
+	"github.com/cloudposse/test-helpers/pkg/atmos/lifecycle"
 	"github.com/gruntwork-io/terratest/modules/aws"
 	"github.com/stretchr/testify/require"
 )
@@ -32,7 +33,8 @@ func getAtmosOptions(t *testing.T, config *c.Config, componentName string, stack
 		BackendConfig: map[string]interface{}{
 			"workspace_key_prefix": strings.Join([]string{config.RandomIdentifier, stackName}, "-"),
 		},
-		Vars: mergedVars,
+		Vars:     mergedVars,
+		Recorder: config.Recorder,
 		EnvVars: map[string]string{
 			"ATMOS_BASE_PATH":            config.TempDir,
 			"ATMOS_CLI_CONFIG_PATH":      config.TempDir,
@@ -41,6 +43,9 @@ func getAtmosOptions(t *testing.T, config *c.Config, componentName string, stack
 		},
 		GenerateBackend:    true,
 		InitRunReconfigure: true,
+		RetryPolicy:        atmos.DefaultRetryPolicy(),
 	}
+	lifecycle.SetPluginOptions(config, atmosOptions)
+	lifecycle.SetLocalBackendOptions(config, atmosOptions)
 	return atmosOptions
 }
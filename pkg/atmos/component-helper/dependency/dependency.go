@@ -1,7 +1,17 @@
 package dependency
 
-type Dependency struct {
-	AdditionalVars *map[string]interface{}
-	ComponentName  string
-	StackName      string
+import (
+	"github.com/cloudposse/test-helpers/pkg/atmos/lifecycle"
+	"github.com/cloudposse/test-helpers/pkg/dag"
+)
+
+// Dependency is a component, workflow or function the test suite deploys before its tests run.
+type Dependency = lifecycle.Dependency
+
+// ComponentRef refers to an atmos component in a stack, see lifecycle.ComponentRef.
+type ComponentRef = lifecycle.ComponentRef
+
+// Graph builds the dependency graph of the given dependencies, see lifecycle.Graph.
+func Graph(dependencies []*Dependency) (*dag.Graph, error) {
+	return lifecycle.Graph(dependencies)
 }
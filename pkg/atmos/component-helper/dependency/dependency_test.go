@@ -0,0 +1,46 @@
+package dependency
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cloudposse/test-helpers/pkg/dag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphOrdersDependenciesByDependsOn(t *testing.T) {
+	dependencies := []*Dependency{
+		{ComponentName: "eks", StackName: "test", DependsOn: []string{"vpc"}},
+		{ComponentName: "dns", StackName: "test"},
+		{ComponentName: "vpc", StackName: "test"},
+	}
+
+	g, err := Graph(dependencies)
+	require.NoError(t, err)
+
+	order, err := g.TopologicalOrder()
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 0}, order)
+}
+
+func TestGraphFailsOnUnknownDependency(t *testing.T) {
+	dependencies := []*Dependency{
+		{ComponentName: "eks", StackName: "test", DependsOn: []string{"vpc"}},
+	}
+
+	_, err := Graph(dependencies)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `depends on "vpc"`)
+}
+
+func TestGraphFailsOnCycles(t *testing.T) {
+	dependencies := []*Dependency{
+		{ComponentName: "eks", StackName: "test", DependsOn: []string{"vpc"}},
+		{ComponentName: "vpc", StackName: "test", DependsOn: []string{"eks"}},
+	}
+
+	_, err := Graph(dependencies)
+	var cycleErr dag.CycleError
+	require.True(t, errors.As(err, &cycleErr))
+}
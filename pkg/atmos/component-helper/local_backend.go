@@ -0,0 +1,25 @@
+package component_helper
+
+import (
+	"path/filepath"
+	"testing"
+
+	c "github.com/cloudposse/test-helpers/pkg/atmos/component-helper/config"
+	"github.com/cloudposse/test-helpers/pkg/atmos/lifecycle"
+	"github.com/stretchr/testify/require"
+)
+
+// GenerateLocalBackends writes a local backend override into every component of the temp dir when Config.LocalBackend
+// is set, see lifecycle.Engine.GenerateLocalBackends.
+func (s *TestSuite) GenerateLocalBackends(t *testing.T, config *c.Config) {
+	s.engine.GenerateLocalBackends(t, config, filepath.Join(config.TempDir, "components", "terraform"))
+}
+
+// restoreLocalBackend writes the local backend override into the component directory again after it was replaced.
+func (s *TestSuite) restoreLocalBackend(t *testing.T, destPath string) {
+	if !s.Config.LocalBackend {
+		return
+	}
+	err := lifecycle.WriteLocalBackendOverrides(destPath)
+	require.NoError(t, err)
+}
@@ -0,0 +1,89 @@
+package component_helper
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudposse/test-helpers/pkg/atmos/lifecycle"
+	"github.com/stretchr/testify/require"
+)
+
+// logPhaseStatus logs the status of a phase with the suite's lifecycle engine.
+func (s *TestSuite) logPhaseStatus(phaseName, status string) {
+	s.engine.LogPhaseStatus(s.T(), phaseName, status)
+}
+
+// runPhase runs a setup or teardown phase as a subtest named after the phase, see lifecycle.Engine.RunPhase. It
+// returns false if the phase failed.
+func (s *TestSuite) runPhase(t *testing.T, phaseName string, fn func(t *testing.T)) bool {
+	return s.engine.RunPhase(t, phaseName, fn)
+}
+
+// runDependency runs the deploy or destroy of a single dependency as a subtest of the phase, see
+// lifecycle.Engine.RunDependency.
+func (s *TestSuite) runDependency(t *testing.T, phaseName string, name string, fn func(t *testing.T) error) error {
+	return s.engine.RunDependency(t, phaseName, name, fn)
+}
+
+// BeforePhase registers hooks that run before the phase with the given name, or before every phase for
+// lifecycle.AnyPhase. If a hook fails, the phase fails without running.
+func (s *TestSuite) BeforePhase(phaseName string, hooks ...lifecycle.Hook) {
+	s.engine.Hooks.BeforePhase(phaseName, hooks...)
+}
+
+// AfterPhase registers hooks that run after the phase with the given name completed, e.g. to seed data once the
+// dependencies are deployed.
+func (s *TestSuite) AfterPhase(phaseName string, hooks ...lifecycle.Hook) {
+	s.engine.Hooks.AfterPhase(phaseName, hooks...)
+}
+
+// OnPhaseFailure registers hooks that run when the phase with the given name fails, e.g. to collect diagnostics.
+func (s *TestSuite) OnPhaseFailure(phaseName string, hooks ...lifecycle.Hook) {
+	s.engine.Hooks.OnPhaseFailure(phaseName, hooks...)
+}
+
+// lifecyclePhases returns the setup and teardown phases of the suite. They are composed on first use and then passed to
+// CustomizePhases, if set.
+func (s *TestSuite) lifecyclePhases() *lifecycle.Phases {
+	if s.phases != nil {
+		return s.phases
+	}
+
+	config := s.Config
+	steps := lifecycle.SuiteSteps{
+		ComponentsDir: filepath.Join("components", "terraform"),
+		Prepare: []lifecycle.Phase{
+			{Name: "setup/bootstrap temp dir", Run: func(t *testing.T) {
+				s.BootstrapTempDir(t, config)
+				if s.State != nil {
+					err := s.State.SetTempDir(config.TempDir)
+					require.NoError(t, err)
+				}
+			}},
+			{Name: "setup/copy component to temp dir", Run: func(t *testing.T) { s.CopyComponentToTempDir(t, config) }},
+		},
+		BeforeDeploy: []lifecycle.Phase{
+			{Name: "setup/validate stacks", Run: func(t *testing.T) { s.ValidateStacks(t, config) }},
+			{Name: "setup/resolve stack dependencies", Run: func(t *testing.T) { s.ResolveStackDependencies(t, config) }},
+		},
+		DeployDependencies:  func(t *testing.T) { s.DeployDependencies(t, config) },
+		DestroyDependencies: func(t *testing.T) { s.DestroyDependencies(t, config) },
+		DestroyTempDir:      func(t *testing.T) { s.DestroyTempDir(t, config) },
+	}
+	if s.LocalStack != nil {
+		steps.Prepare = append(steps.Prepare, lifecycle.Phase{Name: "setup/localstack container", Run: func(t *testing.T) {
+			s.engine.SetupLocalStack(t, s.T(), config, s.LocalStack)
+		}})
+		steps.DestroyLocalStack = func(t *testing.T) { s.engine.DestroyLocalStack(t, config, s.LocalStack) }
+	}
+	steps.Prepare = append(steps.Prepare, lifecycle.Phase{Name: "vendor dependencies", Run: func(t *testing.T) { s.VendorDependencies(t, config) }})
+
+	phases := s.engine.SuitePhases(s.T(), config, steps)
+
+	if s.CustomizePhases != nil {
+		s.CustomizePhases(phases)
+	}
+
+	s.phases = phases
+	return s.phases
+}
@@ -0,0 +1,91 @@
+package component_helper
+
+import (
+	"errors"
+	"testing"
+
+	c "github.com/cloudposse/test-helpers/pkg/atmos/component-helper/config"
+	"github.com/cloudposse/test-helpers/pkg/atmos/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPhaseReportsPhaseAsSubtest(t *testing.T) {
+	s := NewTestSuite()
+	s.SetT(t)
+
+	var phaseT *testing.T
+	ok := s.runPhase(t, "setup/bootstrap temp dir", func(t *testing.T) {
+		phaseT = t
+		s.logPhaseStatus("setup/bootstrap temp dir", "completed")
+	})
+	require.True(t, ok)
+	assert.Equal(t, t.Name()+"/setup/bootstrap_temp_dir", phaseT.Name())
+	assert.False(t, phaseT.Skipped())
+}
+
+func TestRunPhaseReportsSkippedPhase(t *testing.T) {
+	s := NewTestSuite()
+	s.SetT(t)
+
+	var phaseT *testing.T
+	ok := s.runPhase(t, "setup/validate stacks", func(t *testing.T) {
+		phaseT = t
+		s.logPhaseStatus("setup/validate stacks", "skipped")
+	})
+	require.True(t, ok)
+	assert.True(t, phaseT.Skipped())
+}
+
+func TestRunDependency(t *testing.T) {
+	s := NewTestSuite()
+	s.SetT(t)
+
+	var dependencyT *testing.T
+	err := s.runDependency(t, "deploy dependencies", "default-test/vpc", func(t *testing.T) error {
+		dependencyT = t
+		t.Skip("already deployed by a previous run")
+		return errors.New("unreachable")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, t.Name()+"/default-test/vpc", dependencyT.Name())
+	assert.True(t, dependencyT.Skipped())
+
+	err = s.runDependency(t, "deploy dependencies", "default-test/vpc", func(t *testing.T) error { return nil })
+	assert.NoError(t, err)
+}
+
+func TestRunPhaseRecordsPhasesInReport(t *testing.T) {
+	s := NewTestSuite()
+	s.SetT(t)
+	s.Config = &c.Config{}
+	s.InitReport()
+	require.NotNil(t, s.Config.Recorder)
+
+	s.runPhase(t, "setup/validate stacks", func(t *testing.T) { s.logPhaseStatus("setup/validate stacks", "skipped") })
+	s.runPhase(t, "deploy dependencies", func(t *testing.T) {
+		err := s.runDependency(t, "deploy dependencies", "default-test/vpc", func(t *testing.T) error { return nil })
+		require.NoError(t, err)
+	})
+
+	phases := s.Report.Phases()
+	require.Len(t, phases, 3)
+	assert.Equal(t, "setup/validate stacks", phases[0].Name)
+	assert.Equal(t, report.StatusSkipped, phases[0].Status)
+	assert.Equal(t, "deploy dependencies/default-test/vpc", phases[1].Name)
+	assert.Equal(t, report.StatusPassed, phases[1].Status)
+	assert.Equal(t, "deploy dependencies", phases[2].Name)
+	assert.Equal(t, report.StatusPassed, phases[2].Status)
+}
+
+func TestLifecyclePhasesCollectDiagnosticsFirstInTeardown(t *testing.T) {
+	s := NewTestSuite()
+	s.SetT(t)
+	s.Config = &c.Config{DiagnosticsDir: t.TempDir()}
+	s.InitReport()
+	s.InitDiagnostics()
+
+	phases := s.lifecyclePhases()
+	require.NotEmpty(t, phases.Teardown)
+	assert.Equal(t, "teardown/collect diagnostics", phases.Teardown[0].Name)
+}
@@ -0,0 +1,20 @@
+package component_helper
+
+import "testing"
+
+// InitReport creates the run report of the suite and makes the atmos options built from the config record every atmos
+// command in it.
+func (s *TestSuite) InitReport() {
+	s.Report = s.engine.InitReport(s.T(), s.Config, s.Report)
+}
+
+// InitDiagnostics enables the diagnostics bundle collected into Config.DiagnosticsDir when a phase or test fails. It
+// runs after InitReport, so that every atmos command is recorded in both the run report and the bundle.
+func (s *TestSuite) InitDiagnostics() {
+	s.engine.EnableDiagnostics(s.Config)
+}
+
+// WriteReport writes the JUnit XML and JSON run reports to Config.ReportDir. Nothing is written if ReportDir is empty.
+func (s *TestSuite) WriteReport(t *testing.T) {
+	s.engine.WriteReport(t, s.Config.ReportDir)
+}
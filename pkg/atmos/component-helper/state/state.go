@@ -0,0 +1,209 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	StatusStarted   = "started"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// PhaseRecord is the last status recorded for a test suite phase.
+type PhaseRecord struct {
+	Status    string    `yaml:"status"`
+	UpdatedAt time.Time `yaml:"updated_at"`
+}
+
+// DependencyRecord is the outcome of applying and destroying a single dependency of the test suite.
+type DependencyRecord struct {
+	ComponentName string    `yaml:"component"`
+	StackName     string    `yaml:"stack"`
+	Apply         string    `yaml:"apply,omitempty"`
+	Destroy       string    `yaml:"destroy,omitempty"`
+	UpdatedAt     time.Time `yaml:"updated_at"`
+}
+
+// Ledger records which phases of a test suite ran and which dependencies were applied and destroyed. It is saved to
+// disk after every change so that a later run of the suite against the same temp dir can resume where an interrupted
+// run stopped, and tear down exactly what was created.
+type Ledger struct {
+	TempDir      string                  `yaml:"temp_dir"`
+	Phases       map[string]*PhaseRecord `yaml:"phases"`
+	Dependencies []*DependencyRecord     `yaml:"dependencies"`
+
+	path string
+	mu   sync.Mutex
+}
+
+// PathForConfig returns the path of the ledger kept next to the given test suite config file, e.g. test_suite.yaml is
+// paired with test_suite.state.yaml.
+func PathForConfig(configFilePath string) string {
+	ext := filepath.Ext(configFilePath)
+	return strings.TrimSuffix(configFilePath, ext) + ".state" + ext
+}
+
+// Load reads the ledger at path. If the file does not exist, an empty ledger that will be saved to path is returned.
+func Load(path string) (*Ledger, error) {
+	l := &Ledger{path: path}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		l.reset("")
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(content, l); err != nil {
+		return nil, err
+	}
+	if l.Phases == nil {
+		l.Phases = map[string]*PhaseRecord{}
+	}
+	return l, nil
+}
+
+// Path returns the file the ledger is saved to.
+func (l *Ledger) Path() string {
+	return l.path
+}
+
+// Reset discards everything recorded so far and associates the ledger with tempDir.
+func (l *Ledger) Reset(tempDir string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.reset(tempDir)
+	return l.save()
+}
+
+func (l *Ledger) reset(tempDir string) {
+	l.TempDir = tempDir
+	l.Phases = map[string]*PhaseRecord{}
+	l.Dependencies = nil
+}
+
+// SetTempDir associates the ledger with the temp dir the suite runs in.
+func (l *Ledger) SetTempDir(tempDir string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.TempDir = tempDir
+	return l.save()
+}
+
+// SetPhase records the status of a phase.
+func (l *Ledger) SetPhase(phaseName string, status string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.Phases[phaseName] = &PhaseRecord{Status: status, UpdatedAt: time.Now().UTC()}
+	return l.save()
+}
+
+// PhaseCompleted reports whether the phase completed successfully in this or a previous run.
+func (l *Ledger) PhaseCompleted(phaseName string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	phase, ok := l.Phases[phaseName]
+	return ok && phase.Status == StatusCompleted
+}
+
+// SetApplyStatus records the status of applying the dependency. Recording a new apply clears any previous destroy
+// status.
+func (l *Ledger) SetApplyStatus(componentName string, stackName string, status string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record := l.dependency(componentName, stackName)
+	record.Apply = status
+	record.Destroy = ""
+	record.UpdatedAt = time.Now().UTC()
+	return l.save()
+}
+
+// SetDestroyStatus records the status of destroying the dependency.
+func (l *Ledger) SetDestroyStatus(componentName string, stackName string, status string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record := l.dependency(componentName, stackName)
+	record.Destroy = status
+	record.UpdatedAt = time.Now().UTC()
+	return l.save()
+}
+
+// Applied reports whether the dependency was applied successfully and has not been destroyed since.
+func (l *Ledger) Applied(componentName string, stackName string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record := l.find(componentName, stackName)
+	return record != nil && record.Apply == StatusCompleted && record.Destroy != StatusCompleted
+}
+
+// NeedsDestroy reports whether an apply of the dependency was attempted, even if it failed or was interrupted, and the
+// dependency has not been destroyed successfully since.
+func (l *Ledger) NeedsDestroy(componentName string, stackName string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record := l.find(componentName, stackName)
+	return record != nil && record.Apply != "" && record.Destroy != StatusCompleted
+}
+
+// Remove deletes the ledger file.
+func (l *Ledger) Remove() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	err := os.Remove(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (l *Ledger) find(componentName string, stackName string) *DependencyRecord {
+	for _, record := range l.Dependencies {
+		if record.ComponentName == componentName && record.StackName == stackName {
+			return record
+		}
+	}
+	return nil
+}
+
+func (l *Ledger) dependency(componentName string, stackName string) *DependencyRecord {
+	record := l.find(componentName, stackName)
+	if record == nil {
+		record = &DependencyRecord{ComponentName: componentName, StackName: stackName}
+		l.Dependencies = append(l.Dependencies, record)
+	}
+	return record
+}
+
+// save writes the ledger to a temporary file and renames it into place, so an interrupted write never leaves a
+// truncated ledger behind.
+func (l *Ledger) save() error {
+	content, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
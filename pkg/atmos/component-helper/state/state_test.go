@@ -0,0 +1,74 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathForConfig(t *testing.T) {
+	assert.Equal(t, "test_suite.state.yaml", PathForConfig("test_suite.yaml"))
+	assert.Equal(t, filepath.Join("configs", "vpc.state.yml"), PathForConfig(filepath.Join("configs", "vpc.yml")))
+}
+
+func TestLoadMissingLedgerIsEmpty(t *testing.T) {
+	ledger, err := Load(filepath.Join(t.TempDir(), "test_suite.state.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, ledger.TempDir)
+	assert.Empty(t, ledger.Phases)
+	assert.Empty(t, ledger.Dependencies)
+}
+
+func TestLedgerIsPersistedAcrossLoads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test_suite.state.yaml")
+
+	ledger, err := Load(path)
+	require.NoError(t, err)
+	require.NoError(t, ledger.SetTempDir("/tmp/atmos-test-helper123"))
+	require.NoError(t, ledger.SetPhase("vendor dependencies", StatusCompleted))
+	require.NoError(t, ledger.SetApplyStatus("vpc", "test-use2-sandbox", StatusCompleted))
+	require.NoError(t, ledger.SetApplyStatus("eks", "test-use2-sandbox", StatusFailed))
+
+	reloaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/atmos-test-helper123", reloaded.TempDir)
+	assert.True(t, reloaded.PhaseCompleted("vendor dependencies"))
+	assert.False(t, reloaded.PhaseCompleted("deploy dependencies"))
+	assert.True(t, reloaded.Applied("vpc", "test-use2-sandbox"))
+	assert.False(t, reloaded.Applied("eks", "test-use2-sandbox"))
+}
+
+func TestNeedsDestroy(t *testing.T) {
+	ledger, err := Load(filepath.Join(t.TempDir(), "test_suite.state.yaml"))
+	require.NoError(t, err)
+
+	require.NoError(t, ledger.SetApplyStatus("vpc", "test-use2-sandbox", StatusCompleted))
+	require.NoError(t, ledger.SetApplyStatus("eks", "test-use2-sandbox", StatusStarted))
+
+	assert.True(t, ledger.NeedsDestroy("vpc", "test-use2-sandbox"))
+	assert.True(t, ledger.NeedsDestroy("eks", "test-use2-sandbox"), "an interrupted apply may have created resources")
+	assert.False(t, ledger.NeedsDestroy("rds", "test-use2-sandbox"), "a dependency that was never applied has nothing to destroy")
+
+	require.NoError(t, ledger.SetDestroyStatus("vpc", "test-use2-sandbox", StatusFailed))
+	assert.True(t, ledger.NeedsDestroy("vpc", "test-use2-sandbox"))
+
+	require.NoError(t, ledger.SetDestroyStatus("vpc", "test-use2-sandbox", StatusCompleted))
+	assert.False(t, ledger.NeedsDestroy("vpc", "test-use2-sandbox"))
+	assert.False(t, ledger.Applied("vpc", "test-use2-sandbox"))
+}
+
+func TestResetAndRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test_suite.state.yaml")
+	ledger, err := Load(path)
+	require.NoError(t, err)
+	require.NoError(t, ledger.SetApplyStatus("vpc", "test-use2-sandbox", StatusCompleted))
+
+	require.NoError(t, ledger.Reset(""))
+	assert.False(t, ledger.NeedsDestroy("vpc", "test-use2-sandbox"))
+
+	require.NoError(t, ledger.Remove())
+	require.NoFileExists(t, path)
+	require.NoError(t, ledger.Remove())
+}
@@ -1,14 +1,17 @@
 package component_helper
 
 import (
-	"testing"
-
 	"fmt"
+	"testing"
 
 	"dario.cat/mergo"
+	log "github.com/charmbracelet/log"
 	"github.com/cloudposse/test-helpers/pkg/atmos"
 	c "github.com/cloudposse/test-helpers/pkg/atmos/component-helper/config"
 	"github.com/cloudposse/test-helpers/pkg/atmos/component-helper/dependency"
+	"github.com/cloudposse/test-helpers/pkg/atmos/component-helper/state"
+	"github.com/cloudposse/test-helpers/pkg/atmos/lifecycle"
+	"github.com/cloudposse/test-helpers/pkg/atmos/report"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -17,7 +20,21 @@ import (
 type TestSuite struct {
 	Config       *c.Config
 	Dependencies []*dependency.Dependency
+	State        *state.Ledger
+	Report       *report.Reporter
 	suite.Suite
+
+	// LocalStack runs the suite against a localstack container when set, e.g. to lifecycle.NewLocalStackConfiguration().
+	LocalStack *lifecycle.LocalStackConfiguration
+
+	// CustomizePhases is called with the setup and teardown phases of the suite before they run, to add, replace or
+	// remove phases.
+	CustomizePhases func(phases *lifecycle.Phases)
+
+	stackDependencyRoots []stackDependencyRoot
+
+	engine lifecycle.Engine
+	phases *lifecycle.Phases
 }
 
 type TestingSuite interface {
@@ -41,11 +58,31 @@ func (s *TestSuite) GetConfig(t *testing.T) *c.Config {
 	return s.Config
 }
 
-func (s *TestSuite) AddDependency(t *testing.T, componentName string, stackName string, additionalVars *map[string]interface{}) {
+// AddDependency registers a component that must be deployed before the tests run and destroyed afterwards. dependsOn
+// lists the component names of previously or subsequently added dependencies that must be deployed before this one.
+func (s *TestSuite) AddDependency(t *testing.T, componentName string, stackName string, additionalVars *map[string]interface{}, dependsOn ...string) {
 	s.Dependencies = append(s.Dependencies, &dependency.Dependency{
 		AdditionalVars: additionalVars,
 		ComponentName:  componentName,
 		StackName:      stackName,
+		DependsOn:      dependsOn,
+	})
+}
+
+// AddWorkflowDependency registers an atmos workflow that must run before the tests run. There is nothing to destroy
+// afterwards.
+func (s *TestSuite) AddWorkflowDependency(t *testing.T, workflowName string, workflowFile string) {
+	s.Dependencies = append(s.Dependencies, &dependency.Dependency{
+		WorkflowName: workflowName,
+		WorkflowFile: workflowFile,
+	})
+}
+
+// AddFunctionDependency registers a function that must run before the tests run. There is nothing to destroy
+// afterwards.
+func (s *TestSuite) AddFunctionDependency(t *testing.T, fn func() error) {
+	s.Dependencies = append(s.Dependencies, &dependency.Dependency{
+		Function: fn,
 	})
 }
 
@@ -76,6 +113,7 @@ func (s *TestSuite) DeployAtmosComponent(t *testing.T, componentName string, sta
 
 	mergedVars := s.getMergedVars(t, additionalVars)
 	atmosOptions := getAtmosOptions(t, s.Config, componentName, stackName, &mergedVars)
+	s.engine.AddDiagnosticsTarget(atmosOptions)
 
 	if s.Config.SkipDeployComponent {
 		s.logPhaseStatus(phaseName, "skipped")
@@ -103,6 +141,11 @@ func (s *TestSuite) DestroyAtmosComponent(t *testing.T, componentName string, st
 
 	s.logPhaseStatus(phaseName, "started")
 
+	// The component of a failed test is about to be destroyed, so its diagnostics are collected first.
+	if t.Failed() {
+		s.engine.CollectDiagnostics(t, s.Config)
+	}
+
 	mergedVars := s.getMergedVars(t, additionalVars)
 	atmosOptions := getAtmosOptions(t, s.Config, componentName, stackName, &mergedVars)
 
@@ -121,6 +164,37 @@ func (s *TestSuite) InitConfig() {
 	}
 }
 
+// InitState loads the state ledger kept next to the config file. A ledger recorded for a different temp dir belongs to
+// a run whose temp dir is gone, so it is discarded.
+func (s *TestSuite) InitState() {
+	t := s.T()
+
+	// Skipped phases are not recorded, so that a phase completed in a previous run is still known to be complete.
+	s.engine.OnPhaseStatus = func(t *testing.T, phaseName string, status string) {
+		if s.State == nil || status == "skipped" {
+			return
+		}
+		if err := s.State.SetPhase(phaseName, status); err != nil {
+			log.WithPrefix(t.Name()).Warn("failed to record phase status in state ledger", "phase", phaseName, "error", err)
+		}
+	}
+
+	if s.State != nil {
+		return
+	}
+
+	ledger, err := state.Load(state.PathForConfig(s.Config.ConfigFilePath))
+	require.NoError(t, err)
+
+	if ledger.TempDir != "" && ledger.TempDir != s.Config.TempDir {
+		log.WithPrefix(t.Name()).Warn("discarding state ledger recorded for another temp dir", "path", ledger.Path(), "tempDir", ledger.TempDir)
+		err = ledger.Reset("")
+		require.NoError(t, err)
+	}
+
+	s.State = ledger
+}
+
 func (s *TestSuite) BeforeTest(suiteName, testName string) {
 	if s.Config.OnlyDeployDependencies {
 		s.T().Skip("Skipping test because OnlyDeployDependencies is true")
@@ -132,7 +206,10 @@ func (s *TestSuite) SetupSuite() {
 	t := s.T()
 
 	s.InitConfig()
-	config := s.Config
+	s.InitState()
+	s.InitReport()
+	s.InitDiagnostics()
+	s.InitPluginCache()
 
 	if s.Config.SkipSetupTestSuite {
 		s.logPhaseStatus("setup", "skipped")
@@ -140,25 +217,23 @@ func (s *TestSuite) SetupSuite() {
 		s.logPhaseStatus("setup", "started")
 	}
 
-	s.BootstrapTempDir(t, config)
-	s.CopyComponentToTempDir(t, config)
-	s.VendorDependencies(t, config)
-	s.DeployDependencies(t, config)
+	// Every phase runs as a subtest, so that a failure is reported against the phase it happened in.
+	s.engine.RunSetup(t, s.lifecyclePhases().Setup)
 
 	s.logPhaseStatus("setup", "completed")
 }
 
 func (s *TestSuite) TearDownSuite() {
 	t := s.T()
-	if !s.Config.SkipDestroyDependencies {
-		s.DestroyDependencies(t, s.Config)
-	}
+
+	// The report is written last, so that it includes every teardown phase.
+	defer s.WriteReport(t)
+
+	// Teardown phases run even when an earlier one failed, so that as much as possible is cleaned up. A failed phase
+	// still fails the suite.
+	s.engine.RunTeardown(t, s.lifecyclePhases().Teardown)
 
 	if s.Config.SkipTeardownTestSuite {
 		s.logPhaseStatus("teardown", "skipped")
-		return
 	}
-
-	s.DestroyTempDir(t, s.Config)
-	s.DestroyConfigFile(t, s.Config)
 }
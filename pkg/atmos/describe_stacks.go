@@ -1,5 +1,12 @@
 package atmos
 
+import (
+	"encoding/json"
+
+	"github.com/cloudposse/test-helpers/pkg/testing"
+	"github.com/stretchr/testify/require"
+)
+
 type DescribeStacksTerraformComponent struct {
 	AtmosComponent         string      `json:"atmos_component"`
 	AtmosStack             string      `json:"atmos_stack"`
@@ -24,9 +31,84 @@ type DescribeStacksTerraform struct {
 }
 
 type DescribeStacksComponent struct {
+	Components DescribeStacksTerraform `json:"components"`
+
+	// Deprecated: atmos describe stacks has no component section, so Component is always empty. Use Components instead.
 	Component map[string]any `json:"component"`
 }
 
 type DescribeStacksOutput struct {
 	Stacks map[string]DescribeStacksComponent
 }
+
+// DescribeStacks runs atmos describe stacks with the given options and returns the parsed output. If Stack or Component
+// are set on the options, the output is limited to that stack or component. Any filters are passed to atmos as
+// additional arguments (e.g. "--component-types=terraform"). This will fail the test if there is an error in the
+// command or in parsing the output.
+func DescribeStacks(t testing.TestingT, options *Options, filters ...string) *DescribeStacksOutput {
+	out, err := DescribeStacksE(t, options, filters...)
+	require.NoError(t, err)
+	return out
+}
+
+// DescribeStacksE runs atmos describe stacks with the given options and returns the parsed output. If Stack or
+// Component are set on the options, the output is limited to that stack or component. Any filters are passed to atmos
+// as additional arguments (e.g. "--component-types=terraform").
+func DescribeStacksE(t testing.TestingT, options *Options, filters ...string) (*DescribeStacksOutput, error) {
+	args := []string{"describe", "stacks", "--format", "json"}
+	if options.Stack != "" {
+		args = append(args, "--stack", options.Stack)
+	}
+	if options.Component != "" {
+		args = append(args, "--components", options.Component)
+	}
+	args = append(args, filters...)
+
+	out, err := RunAtmosCommandAndGetStdoutE(t, options, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseDescribeStacksOutput(cleanOutput(out))
+}
+
+// DescribeComponent runs atmos describe component for the Component and Stack set on the options and returns the fully
+// resolved component configuration. This will fail the test if there is an error in the command or in parsing the
+// output.
+func DescribeComponent(t testing.TestingT, options *Options) *DescribeStacksTerraformComponent {
+	out, err := DescribeComponentE(t, options)
+	require.NoError(t, err)
+	return out
+}
+
+// DescribeComponentE runs atmos describe component for the Component and Stack set on the options and returns the
+// fully resolved component configuration.
+func DescribeComponentE(t testing.TestingT, options *Options) (*DescribeStacksTerraformComponent, error) {
+	if options.Component == "" {
+		return nil, ErrorComponentRequired
+	}
+
+	if options.Stack == "" {
+		return nil, ErrorStackRequired
+	}
+
+	args := []string{"describe", "component", options.Component, "-s", options.Stack, "--format", "json"}
+	out, err := RunAtmosCommandAndGetStdoutE(t, options, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	component := &DescribeStacksTerraformComponent{}
+	if err := json.Unmarshal([]byte(cleanOutput(out)), component); err != nil {
+		return nil, err
+	}
+	return component, nil
+}
+
+// parseDescribeStacksOutput unmarshals the json output of atmos describe stacks, which is keyed by stack name.
+func parseDescribeStacksOutput(out string) (*DescribeStacksOutput, error) {
+	stacks := map[string]DescribeStacksComponent{}
+	if err := json.Unmarshal([]byte(out), &stacks); err != nil {
+		return nil, err
+	}
+	return &DescribeStacksOutput{Stacks: stacks}, nil
+}
@@ -0,0 +1,59 @@
+package atmos
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/files"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeComponent(t *testing.T) {
+	t.Parallel()
+
+	testFolder, err := files.CopyTerraformFolderToTemp(atmosExamplePath, t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(testFolder)
+
+	options := &Options{
+		AtmosBasePath: testFolder,
+		Component:     "terraform-no-error",
+		Stack:         testStack,
+	}
+
+	component := DescribeComponent(t, options)
+	require.Equal(t, "terraform-no-error", component.AtmosComponent)
+	require.Equal(t, testStack, component.AtmosStack)
+
+	vars, ok := component.Vars.(map[string]interface{})
+	require.True(t, ok)
+	require.Equal(t, "atmos", vars["namespace"])
+}
+
+func TestDescribeStacks(t *testing.T) {
+	t.Parallel()
+
+	testFolder, err := files.CopyTerraformFolderToTemp(atmosExamplePath, t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(testFolder)
+
+	options := &Options{
+		AtmosBasePath: testFolder,
+	}
+
+	out := DescribeStacks(t, options)
+	require.Contains(t, out.Stacks, testStack)
+	require.Contains(t, out.Stacks[testStack].Components.Terraform, "terraform-no-error")
+}
+
+func TestParseDescribeStacksOutput(t *testing.T) {
+	t.Parallel()
+
+	out, err := parseDescribeStacksOutput(`{"test-test-test":{"components":{"terraform":{"vpc":{"atmos_component":"vpc","atmos_stack":"test-test-test","backend_type":"local","vars":{"enabled":true}}}}}}`)
+	require.NoError(t, err)
+
+	vpc := out.Stacks[testStack].Components.Terraform["vpc"]
+	require.Equal(t, "vpc", vpc.AtmosComponent)
+	require.Equal(t, "local", vpc.BackendType)
+	require.Equal(t, map[string]interface{}{"enabled": true}, vpc.Vars)
+}
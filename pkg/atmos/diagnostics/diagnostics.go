@@ -0,0 +1,294 @@
+// Package diagnostics collects what is needed to debug a failed test suite into a tarball that outlives the temp dir:
+// the rendered stack config and terraform state of the components it deployed, the backend and varfiles atmos
+// generated, the last plan and the full log of every atmos command it ran.
+package diagnostics
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudposse/test-helpers/pkg/atmos"
+	"github.com/cloudposse/test-helpers/pkg/testing"
+)
+
+// Collector records atmos commands with their full output and the components a suite deployed, and writes them to a
+// diagnostics bundle when the suite fails. It implements atmos.CommandRecorder, so it can be set as the Recorder of
+// atmos.Options. It is safe for concurrent use.
+type Collector struct {
+	mu       sync.Mutex
+	commands []atmos.CommandRecord
+	targets  []*atmos.Options
+}
+
+// New returns an empty Collector.
+func New() *Collector {
+	return &Collector{}
+}
+
+// RecordCommand records a finished atmos command, keeping all of its output.
+func (c *Collector) RecordCommand(record atmos.CommandRecord) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.commands = append(c.commands, record)
+}
+
+// Commands returns the atmos commands recorded so far.
+func (c *Collector) Commands() []atmos.CommandRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]atmos.CommandRecord(nil), c.commands...)
+}
+
+// AddTarget registers a deployed component, whose rendered stack config and terraform state are collected into the
+// bundle. A component added again for the same stack replaces the options it was added with before.
+func (c *Collector) AddTarget(options *atmos.Options) {
+	if options == nil || options.Component == "" || options.Stack == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, target := range c.targets {
+		if target.Component == options.Component && target.Stack == options.Stack {
+			c.targets[i] = options
+			return
+		}
+	}
+	c.targets = append(c.targets, options)
+}
+
+// Targets returns the components added with AddTarget, in the order they were first added.
+func (c *Collector) Targets() []*atmos.Options {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]*atmos.Options(nil), c.targets...)
+}
+
+// LastPlanJSON returns the plan shown by the last atmos terraform show -json command, or an empty string if no plan
+// was shown.
+func (c *Collector) LastPlanJSON() string {
+	commands := c.Commands()
+	for i := len(commands) - 1; i >= 0; i-- {
+		if !isShowJSON(commands[i].Args) {
+			continue
+		}
+		if plan := planDocument(commands[i].Output); plan != "" {
+			return plan
+		}
+	}
+	return ""
+}
+
+// Collect writes the diagnostics bundle of the failure named name to <dir>/<name>.diagnostics.tar.gz, creating dir if
+// needed, and returns its path. The generated backend and varfiles are collected from tempDir. Collecting is best
+// effort: a part of the bundle that cannot be collected, e.g. because the component was never deployed, is listed in
+// errors.txt in the bundle instead. Only failing to write the bundle returns an error.
+func (c *Collector) Collect(t testing.TestingT, dir string, name string, tempDir string) (string, error) {
+	bundle := &bundle{prefix: atmos.FileName(name, "diagnostics")}
+
+	// The log is taken before the commands below run, so that it ends with the commands that led to the failure.
+	bundle.add("commands.log", formatCommands(c.Commands()))
+	if plan := c.LastPlanJSON(); plan != "" {
+		bundle.add("plan.json", plan)
+	}
+
+	for _, target := range c.Targets() {
+		prefix := filepath.Join("stacks", target.Stack, target.Component)
+
+		// The commands run to collect the bundle are not recorded, neither in the run report nor in later bundles.
+		target, err := target.Clone()
+		if err != nil {
+			bundle.addError(prefix, err)
+			continue
+		}
+		target.Recorder = nil
+
+		args := []string{"describe", "component", target.Component, "-s", target.Stack, "--format", "yaml"}
+		if out, err := atmos.RunAtmosCommandAndGetStdoutE(t, target, args...); err != nil {
+			bundle.addError(prefix+"/describe-component.yaml", err)
+		} else {
+			bundle.add(prefix+"/describe-component.yaml", out)
+		}
+
+		if addresses, err := atmos.StateListE(t, target); err != nil {
+			bundle.addError(prefix+"/state-list.txt", err)
+		} else {
+			bundle.add(prefix+"/state-list.txt", strings.Join(addresses, "\n")+"\n")
+		}
+	}
+
+	if tempDir != "" {
+		if err := bundle.addGeneratedFiles(tempDir); err != nil {
+			bundle.addError("generated", err)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, bundle.prefix+".diagnostics.tar.gz")
+	if err := bundle.write(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// isGenerated returns true for the backend and varfiles atmos generates in a component directory.
+func isGenerated(name string) bool {
+	return name == "backend.tf.json" || name == "backend_override.tf.json" || strings.HasSuffix(name, ".tfvars.json")
+}
+
+// isShowJSON returns true for the arguments of atmos terraform show that print a plan as JSON.
+func isShowJSON(args []string) bool {
+	if len(args) < 2 || args[0] != "terraform" || args[1] != "show" {
+		return false
+	}
+	for _, arg := range args {
+		if arg == "-json" {
+			return true
+		}
+	}
+	return false
+}
+
+// planDocument returns the line of the output of terraform show -json that holds the plan, which terraform prints as a
+// single line.
+func planDocument(out string) string {
+	lines := strings.Split(out, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(line, "{") && json.Valid([]byte(line)) {
+			return line
+		}
+	}
+	return ""
+}
+
+func formatCommands(commands []atmos.CommandRecord) string {
+	var b strings.Builder
+	for _, command := range commands {
+		fmt.Fprintf(&b, "$ %s\n", strings.Join(append([]string{command.Binary}, command.Args...), " "))
+		fmt.Fprintf(&b, "# dir: %s, started: %s, duration: %s, attempts: %d, exit code: %d\n",
+			command.WorkingDir, command.StartedAt.Format(time.RFC3339), command.Duration.Round(time.Millisecond), command.Attempts, command.ExitCode)
+		if command.Err != nil {
+			fmt.Fprintf(&b, "# error: %s\n", command.Err)
+		}
+		b.WriteString(command.Output)
+		if !strings.HasSuffix(command.Output, "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// bundle is the content of a diagnostics bundle, keyed by the path of each file relative to the bundle directory.
+type bundle struct {
+	prefix string
+	files  map[string]string
+	errors []string
+}
+
+func (b *bundle) add(name string, content string) {
+	if b.files == nil {
+		b.files = map[string]string{}
+	}
+	b.files[filepath.ToSlash(name)] = content
+}
+
+func (b *bundle) addError(name string, err error) {
+	b.errors = append(b.errors, fmt.Sprintf("%s: %s", filepath.ToSlash(name), err))
+}
+
+// addGeneratedFiles adds the backend and varfiles generated in the components under tempDir. The .terraform directories
+// are skipped, since they hold the providers and modules terraform downloaded.
+func (b *bundle) addGeneratedFiles(tempDir string) error {
+	return filepath.WalkDir(tempDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".terraform" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isGenerated(d.Name()) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(tempDir, path)
+		if err != nil {
+			return err
+		}
+		b.add(filepath.Join("generated", rel), string(content))
+		return nil
+	})
+}
+
+// write writes the bundle as a gzipped tarball to path, with every file under a directory named after the bundle.
+func (b *bundle) write(path string) (err error) {
+	if len(b.errors) > 0 {
+		b.add("errors.txt", strings.Join(b.errors, "\n")+"\n")
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	names := make([]string, 0, len(b.files))
+	for name := range b.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := time.Now()
+	for _, name := range names {
+		content := b.files[name]
+		header := &tar.Header{
+			Name:    b.prefix + "/" + name,
+			Mode:    0644,
+			Size:    int64(len(content)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+var _ atmos.CommandRecorder = (*Collector)(nil)
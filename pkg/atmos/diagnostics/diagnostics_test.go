@@ -0,0 +1,115 @@
+package diagnostics
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudposse/test-helpers/pkg/atmos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readBundle returns the files in the bundle at path, keyed by their name in the tarball.
+func readBundle(t *testing.T, path string) map[string]string {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[header.Name] = string(content)
+	}
+	return files
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestCollectWritesBundle(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFile(t, filepath.Join(tempDir, "components/terraform/vpc/main.tf"), "")
+	writeFile(t, filepath.Join(tempDir, "components/terraform/vpc/backend.tf.json"), `{"terraform":{}}`)
+	writeFile(t, filepath.Join(tempDir, "components/terraform/vpc/test-vpc.terraform.tfvars.json"), `{"name":"vpc"}`)
+	writeFile(t, filepath.Join(tempDir, "components/terraform/vpc/.terraform/modules/label/backend.tf.json"), `{}`)
+
+	executor := atmos.NewFakeExecutor(
+		atmos.FakeResponse{Args: []string{"describe", "component"}, Stdout: "vars:\n  name: vpc\n"},
+		atmos.FakeResponse{Args: []string{"terraform", "state", "list", "eks"}, Stderr: "no state", ExitCode: 1},
+		atmos.FakeResponse{Args: []string{"terraform", "state", "list"}, Stdout: "Switched to workspace \"test-vpc\".\naws_vpc.default\n"},
+	)
+
+	c := New()
+	c.RecordCommand(atmos.CommandRecord{Binary: "atmos", Args: []string{"terraform", "plan", "vpc", "-s", "test"}, Output: "Plan: 1 to add"})
+	c.RecordCommand(atmos.CommandRecord{
+		Binary: "atmos",
+		Args:   []string{"terraform", "show", "vpc", "--skip-init", "-s", "test", "--", "-no-color", "-json", "vpc.planfile"},
+		Output: "Switched to workspace \"test-vpc\".\n{\"format_version\":\"1.2\"}\n",
+	})
+	c.RecordCommand(atmos.CommandRecord{Binary: "atmos", Args: []string{"terraform", "apply", "vpc", "-s", "test"}, ExitCode: 1, Err: errors.New("exit status 1"), Output: "Error: creating VPC"})
+	c.AddTarget(&atmos.Options{Component: "vpc", Stack: "test", Executor: executor, Recorder: c})
+	c.AddTarget(&atmos.Options{Component: "eks", Stack: "test", Executor: executor, Recorder: c})
+	c.AddTarget(&atmos.Options{Component: "vpc", Stack: "test", Executor: executor, Recorder: c})
+
+	dir := filepath.Join(t.TempDir(), "artifacts")
+	path, err := c.Collect(t, dir, "TestSuite/deploy dependencies", tempDir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "TestSuite_deploy_dependencies.diagnostics.tar.gz"), path)
+	assert.Len(t, c.Commands(), 3, "commands run to collect the bundle are not recorded")
+
+	files := readBundle(t, path)
+	prefix := "TestSuite_deploy_dependencies/"
+
+	assert.Contains(t, files[prefix+"commands.log"], "$ atmos terraform apply vpc -s test\n")
+	assert.Contains(t, files[prefix+"commands.log"], "# error: exit status 1\nError: creating VPC\n")
+	assert.NotContains(t, files[prefix+"commands.log"], "describe component", "commands run to collect the bundle are not logged")
+	assert.Equal(t, `{"format_version":"1.2"}`, files[prefix+"plan.json"])
+
+	assert.Equal(t, "vars:\n  name: vpc\n", files[prefix+"stacks/test/vpc/describe-component.yaml"])
+	assert.Equal(t, "aws_vpc.default\n", files[prefix+"stacks/test/vpc/state-list.txt"])
+	assert.NotContains(t, files, prefix+"stacks/test/eks/state-list.txt")
+	assert.Contains(t, files[prefix+"errors.txt"], "stacks/test/eks/state-list.txt: ")
+
+	assert.Equal(t, `{"terraform":{}}`, files[prefix+"generated/components/terraform/vpc/backend.tf.json"])
+	assert.Equal(t, `{"name":"vpc"}`, files[prefix+"generated/components/terraform/vpc/test-vpc.terraform.tfvars.json"])
+	assert.NotContains(t, files, prefix+"generated/components/terraform/vpc/main.tf")
+	assert.NotContains(t, files, prefix+"generated/components/terraform/vpc/.terraform/modules/label/backend.tf.json")
+}
+
+func TestAddTargetReplacesComponentInSameStack(t *testing.T) {
+	c := New()
+	c.AddTarget(&atmos.Options{Component: "vpc", Stack: "test"})
+	c.AddTarget(&atmos.Options{Component: "dns", Stack: "test"})
+	c.AddTarget(&atmos.Options{Component: "vpc", Stack: "test", Vars: map[string]interface{}{"enabled": true}})
+	c.AddTarget(&atmos.Options{Component: "vendored"})
+
+	targets := c.Targets()
+	require.Len(t, targets, 2)
+	assert.Equal(t, "vpc", targets[0].Component)
+	assert.Equal(t, true, targets[0].Vars["enabled"])
+	assert.Equal(t, "dns", targets[1].Component)
+}
+
+func TestLastPlanJSONIsEmptyWithoutShow(t *testing.T) {
+	c := New()
+	c.RecordCommand(atmos.CommandRecord{Args: []string{"terraform", "output", "vpc", "-s", "test", "--", "-json"}, Output: `{"vpc_id":{}}`})
+	assert.Empty(t, c.LastPlanJSON())
+}
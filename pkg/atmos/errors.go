@@ -1,8 +1,13 @@
 package atmos
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+	"time"
 )
 
 // OutputKeyNotFound occurs when terraform output does not contain a value for the key
@@ -93,3 +98,208 @@ type WorkspaceDoesNotExist string
 func (err WorkspaceDoesNotExist) Error() string {
 	return fmt.Sprintf("The workspace %q does not exist.", string(err))
 }
+
+// StackValidationError holds the validation messages reported by atmos for a single stack manifest
+type StackValidationError struct {
+	Manifest string
+	Messages []string
+}
+
+func (err StackValidationError) Error() string {
+	if err.Manifest == "" {
+		return strings.Join(err.Messages, "\n")
+	}
+	return fmt.Sprintf("Stack manifest %q is invalid:\n%s", err.Manifest, strings.Join(err.Messages, "\n"))
+}
+
+// StacksValidationFailed is returned when atmos validate stacks exits with a non-zero exit code because one or more
+// stack manifests are invalid. Underlying is the error of the command.
+type StacksValidationFailed struct {
+	Errors     []StackValidationError
+	Underlying error
+}
+
+func (err StacksValidationFailed) Error() string {
+	messages := make([]string, 0, len(err.Errors))
+	for _, e := range err.Errors {
+		messages = append(messages, e.Error())
+	}
+	return fmt.Sprintf("Stack validation failed:\n%s", strings.Join(messages, "\n"))
+}
+
+func (err StacksValidationFailed) Unwrap() error {
+	return err.Underlying
+}
+
+// ComponentValidationFailed is returned when atmos validate component exits with a non-zero exit code because a
+// component in a stack does not pass its JSON Schema or OPA policy validation. Underlying is the error of the command.
+type ComponentValidationFailed struct {
+	Component  string
+	Stack      string
+	Messages   []string
+	Underlying error
+}
+
+func (err ComponentValidationFailed) Error() string {
+	return fmt.Sprintf("Component %q in stack %q failed validation:\n%s", err.Component, err.Stack, strings.Join(err.Messages, "\n"))
+}
+
+func (err ComponentValidationFailed) Unwrap() error {
+	return err.Underlying
+}
+
+// ResourceChangeNotFound is returned when a plan does not contain a change for the resource address
+type ResourceChangeNotFound string
+
+func (address ResourceChangeNotFound) Error() string {
+	return fmt.Sprintf("Plan doesn't contain a change for the resource %q", string(address))
+}
+
+// UnexpectedResourceChangeActions is returned when the planned actions of a resource are not the expected ones
+type UnexpectedResourceChangeActions struct {
+	Address  string
+	Expected string
+	Actions  []string
+}
+
+func (err UnexpectedResourceChangeActions) Error() string {
+	return fmt.Sprintf("Expected resource %q to be planned to %s but got actions %v", err.Address, err.Expected, err.Actions)
+}
+
+// PlanAttributeNotFound is returned when the planned values of a resource do not contain the attribute path
+type PlanAttributeNotFound struct {
+	Address string
+	Path    string
+}
+
+func (err PlanAttributeNotFound) Error() string {
+	return fmt.Sprintf("Planned values of resource %q don't contain the attribute %q", err.Address, err.Path)
+}
+
+// PlanAttributeUnknown is returned when the value of an attribute is only known after apply
+type PlanAttributeUnknown struct {
+	Address string
+	Path    string
+}
+
+func (err PlanAttributeUnknown) Error() string {
+	return fmt.Sprintf("Attribute %q of resource %q is known only after apply", err.Path, err.Address)
+}
+
+// PlanAttributeMismatch is returned when the planned value of an attribute is not the expected one
+type PlanAttributeMismatch struct {
+	Address  string
+	Path     string
+	Expected interface{}
+	Actual   interface{}
+}
+
+func (err PlanAttributeMismatch) Error() string {
+	return fmt.Sprintf("Expected attribute %q of resource %q to be %v but got %v", err.Path, err.Address, err.Expected, err.Actual)
+}
+
+// PlanHasUnexpectedChanges is returned when a plan contains resource changes that are not allowed, e.g. destroys. It
+// maps the address of each offending resource to its planned actions.
+type PlanHasUnexpectedChanges struct {
+	Allowed string
+	Changes map[string][]string
+}
+
+func (err PlanHasUnexpectedChanges) Error() string {
+	addresses := make([]string, 0, len(err.Changes))
+	for address := range err.Changes {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	lines := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		lines = append(lines, fmt.Sprintf("  %s: %v", address, err.Changes[address]))
+	}
+	return fmt.Sprintf("Expected plan to only %s but got:\n%s", err.Allowed, strings.Join(lines, "\n"))
+}
+
+// CommandFailed is returned when an atmos command fails. Its message includes the stderr of the command, so that
+// RetryableAtmosErrors can match against it.
+type CommandFailed struct {
+	Underlying error
+	Stdout     string
+	Stderr     string
+}
+
+func (err *CommandFailed) Error() string {
+	return fmt.Sprintf("error while running command: %v; %s", err.Underlying, err.Stderr)
+}
+
+func (err *CommandFailed) Unwrap() error {
+	return err.Underlying
+}
+
+// CommandTimedOut is returned when an atmos command, along with every process it started, is killed because it ran
+// longer than Options.Timeout or because its context was done. Cause is context.DeadlineExceeded or context.Canceled.
+type CommandTimedOut struct {
+	Args    []string
+	Timeout time.Duration
+	Cause   error
+}
+
+func (err CommandTimedOut) Error() string {
+	if errors.Is(err.Cause, context.Canceled) {
+		return fmt.Sprintf("atmos %v was cancelled", err.Args)
+	}
+	if err.Timeout > 0 {
+		return fmt.Sprintf("atmos %v timed out after %s", err.Args, err.Timeout)
+	}
+	return fmt.Sprintf("atmos %v did not finish before its context deadline", err.Args)
+}
+
+func (err CommandTimedOut) Unwrap() error {
+	return err.Cause
+}
+
+// NoFakeResponse is returned by a FakeExecutor for a command that matches none of its responses.
+type NoFakeResponse struct {
+	Args []string
+}
+
+func (err NoFakeResponse) Error() string {
+	return fmt.Sprintf("no fake response for atmos %v", err.Args)
+}
+
+// DeprecationWarningsFound is returned when terraform printed deprecation warnings while running a command.
+type DeprecationWarningsFound []Warning
+
+func (err DeprecationWarningsFound) Error() string {
+	lines := make([]string, 0, len(err))
+	for _, warning := range err {
+		if warning.Address != "" {
+			lines = append(lines, fmt.Sprintf("  %s: %s", warning.Address, warning.Summary))
+		} else {
+			lines = append(lines, fmt.Sprintf("  %s", warning.Summary))
+		}
+	}
+	return fmt.Sprintf("Expected no deprecation warnings but got:\n%s", strings.Join(lines, "\n"))
+}
+
+// RetriesExhausted is returned when an atmos command keeps failing with a retryable error after its retry policy allows
+// no more retries.
+type RetriesExhausted struct {
+	Args       []string
+	Retries    int
+	Underlying error
+}
+
+func (err RetriesExhausted) Error() string {
+	return fmt.Sprintf("atmos %v still failed after %d retries: %v", err.Args, err.Retries, err.Underlying)
+}
+
+func (err RetriesExhausted) Unwrap() error {
+	return err.Underlying
+}
+
+// ResourceNotInState is returned when the state of a component does not contain the resource address
+type ResourceNotInState string
+
+func (address ResourceNotInState) Error() string {
+	return fmt.Sprintf("State doesn't contain the resource %q", string(address))
+}
@@ -21,7 +21,8 @@ func (s *TestSuite) CreateTempContents(t *testing.T, config *c.Config) {
 	if s.Config.SkipTempContents || cmd == nil {
 		return
 	}
-	t.Setenv("TEST_TEMP_DIR", config.TempDir)
+	// The environment is set on the suite, since it has to outlive the phase subtest.
+	s.T().Setenv("TEST_TEMP_DIR", config.TempDir)
 	log.WithPrefix(t.Name()).Info("Running Temp Contents Command", "command", cmd.String(), "dir", cmd.Dir)
 
 	//var stdout, stderr bytes.Buffer
@@ -96,6 +96,7 @@ func (s *TestSuite) pullComponentYamlComponents(t *testing.T, config *c.Config)
 		}
 		return nil
 	})
+	s.restoreLocalBackend(t, config)
 
 	s.logPhaseStatus(phaseName, "completed")
 }
@@ -117,6 +118,7 @@ func (s *TestSuite) pullComponent(t *testing.T, config *c.Config, component stri
 
 	// Pull the component
 	_, _ = atmos.VendorPullComponent(t, atmosOptions)
+	s.restoreLocalBackend(t, config)
 }
 
 func (s *TestSuite) VendorAllComponents(t *testing.T, config *c.Config) {
@@ -1,7 +1,6 @@
 package examples_helper
 
 import (
-	"fmt"
 	"testing"
 
 	log "github.com/charmbracelet/log"
@@ -25,47 +24,21 @@ func (s *TestSuite) DeployDependencies(t *testing.T, config *c.Config) {
 		return
 	}
 
-	for _, dependency := range s.Dependencies {
-		if dependency.VendorOnly {
-			log.WithPrefix(t.Name()).Info("skipping vendor only dependency", "component", dependency.ComponentName)
-			continue
-		}
-		if dependency.Function != nil {
-			s.logPhaseStatus("deploy dependencies/function", "started")
-			err := dependency.Function()
-			if err != nil {
-				log.WithPrefix(t.Name()+" deploy function dependency").Error("failed to run function", "error", err)
-			}
-
-			s.logPhaseStatus("deploy dependencies/function", "completed")
-			continue
-		} else if dependency.WorkflowFile != "" || dependency.WorkflowName != "" {
-			if dependency.WorkflowFile == "" || dependency.WorkflowName == "" {
-				log.WithPrefix(t.Name()).Info("skipping Workflow Dependency - Missing WorkflowName or WorkflowFile", "WorkflowName", dependency.WorkflowName, "WorkflowFile", dependency.WorkflowFile)
-				continue
-			}
-			s.logPhaseStatus("deploy dependencies/workflow "+dependency.WorkflowName+" -f "+dependency.WorkflowFile, "started")
-			output, err := atmos.WorkflowE(t, getAtmosOptions(t, config, s, dependency), dependency.WorkflowName, dependency.WorkflowFile)
-			if err != nil {
-				s.logPhaseStatus(phaseName, "failed")
-				log.WithPrefix(t.Name()).Error("failed to run workflow", "WorkflowName", dependency.WorkflowName, "WorkflowFile", dependency.WorkflowFile, "error", err)
-				s.T().FailNow()
-			}
-			log.WithPrefix(t.Name()).WithPrefix(fmt.Sprintf("Workflow [%s -f %s]", dependency.WorkflowName, dependency.WorkflowFile)).Info(output)
-			s.logPhaseStatus("deploy dependencies/workflow "+dependency.WorkflowName+" -f "+dependency.WorkflowFile, "completed")
-		} else {
-
-			log.WithPrefix(t.Name()).Info("deploying dependency", "component", dependency.ComponentName, "stack", dependency.StackName)
-			atmosOptions := getAtmosOptions(t, config, s, dependency)
-			atmosOptions.MergeOptions(dependency.Options)
-			out, err := atmos.ApplyE(t, atmosOptions)
-			log.WithPrefix(t.Name()).Info("deploying dependency", "component", dependency.ComponentName, "stack", dependency.StackName, "output", out)
-			if err != nil {
-				s.logPhaseStatus(phaseName, "failed")
-				require.NoError(t, err)
-			}
-		}
+	// Options are resolved up front because resolving them may fail the test, which must happen on the test goroutine.
+	atmosOptions := make([]*atmos.Options, len(s.Dependencies))
+	for i, dependency := range s.Dependencies {
+		atmosOptions[i] = getAtmosOptions(t, config, s, dependency)
+		atmosOptions[i].MergeOptions(dependency.Options)
+		s.engine.AddDiagnosticsTarget(atmosOptions[i])
+	}
 
+	// Every dependency is deployed in its own subtest, so that a failure is reported against the dependency.
+	err := s.engine.WalkDependencies(t, phaseName, s.Dependencies, config.DependencyParallelism, false, func(t *testing.T, i int) error {
+		return s.engine.DeployDependency(t, s.Dependencies[i], atmosOptions[i])
+	})
+	if err != nil {
+		s.logPhaseStatus(phaseName, "failed")
+		require.NoError(t, err)
 	}
 	s.logPhaseStatus(phaseName, "completed")
 }
@@ -1,8 +1,6 @@
 package examples_helper
 
 import (
-	"github.com/testcontainers/testcontainers-go"
-	"os"
 	"testing"
 
 	log "github.com/charmbracelet/log"
@@ -12,22 +10,7 @@ import (
 )
 
 func (s *TestSuite) DestroyConfigFile(t *testing.T, config *c.Config) {
-	const phaseName = "teardown/destroy config file"
-
-	if anyPhasesSkipped(config) {
-		s.logPhaseStatus(phaseName, "skipped")
-		return
-	}
-
-	s.logPhaseStatus(phaseName, "started")
-
-	err := os.Remove(config.ConfigFilePath)
-	if err != nil {
-		s.logPhaseStatus(phaseName, "failed")
-		require.NoError(t, err)
-	}
-
-	s.logPhaseStatus(phaseName, "completed")
+	s.engine.DestroyConfigFile(t, config)
 }
 
 func (s *TestSuite) DestroyDependencies(t *testing.T, config *c.Config) {
@@ -46,71 +29,34 @@ func (s *TestSuite) DestroyDependencies(t *testing.T, config *c.Config) {
 		return
 	}
 
-	for i := len(s.Dependencies) - 1; i >= 0; i-- {
-		if s.Dependencies[i].VendorOnly {
-			log.WithPrefix(t.Name()).Info("skipping vendor only dependency", "component", s.Dependencies[i].ComponentName)
-			continue
-		}
-
-		if s.Dependencies[i].ComponentName == "tfstate-backend" {
-			log.WithPrefix(t.Name()).Info("skipping tfstate backend dependency", "component", s.Dependencies[i].ComponentName)
-			continue
-			//atmos.Output(t, s.GetAtmosOptions(), "tfstate_backend_s3_bucket_id")
-			//out, err = shell.RunCommandAndGetOutputE(t, shell.Command{
-			//	Command: "aws",
-			//	Args:    []string{"s3", "rm s3 rm s3://bucket-name --recursive"},
-			//})
-		}
+	atmosOptions := make([]*atmos.Options, len(s.Dependencies))
+	for i, dependency := range s.Dependencies {
+		atmosOptions[i] = getAtmosOptions(t, config, s, dependency)
+	}
 
+	// Dependencies are destroyed in reverse topological order, so nothing is destroyed while a dependent still exists.
+	// Every dependency is destroyed in its own subtest, so that a failure is reported against the dependency.
+	err := s.engine.WalkDependencies(t, phaseName, s.Dependencies, config.DependencyParallelism, true, func(t *testing.T, i int) error {
 		dependency := s.Dependencies[i]
-		log.Info("destroying dependency", "component", dependency.ComponentName, "stack", dependency.StackName)
-		atmosOptions := getAtmosOptions(t, config, s, dependency)
-		_, err := atmos.DestroyE(t, atmosOptions)
-		if err != nil {
-			s.logPhaseStatus(phaseName, "failed")
-			require.NoError(t, err)
+		if dependency.ComponentName == "tfstate-backend" {
+			log.WithPrefix(t.Name()).Info("skipping tfstate backend dependency", "component", dependency.ComponentName)
+			t.Skip("tfstate backend dependency")
 		}
+
+		return s.engine.DestroyDependency(t, dependency, atmosOptions[i])
+	})
+	if err != nil {
+		s.logPhaseStatus(phaseName, "failed")
+		require.NoError(t, err)
 	}
 
 	s.logPhaseStatus(phaseName, "completed")
 }
 
 func (s *TestSuite) DestroyTempDir(t *testing.T, config *c.Config) {
-	const phaseName = "teardown/destroy temp dir"
-
-	if anyPhasesSkipped(config) {
-		s.logPhaseStatus(phaseName, "skipped")
-		return
-	}
-
-	s.logPhaseStatus(phaseName, "started")
-
-	log.WithPrefix(t.Name()).Info("removing terraform state directory", "path", config.StateDir)
-	err := os.RemoveAll(config.StateDir)
-	require.NoError(t, err)
-
-	log.WithPrefix(t.Name()).Info("removing temp directory", "path", config.TempDir)
-	err = os.RemoveAll(config.TempDir)
-	require.NoError(t, err)
-
-	s.logPhaseStatus(phaseName, "completed")
+	s.engine.DestroyTempDir(t, config)
 }
 
 func (s *TestSuite) DestroyLocalStackContainer(t *testing.T, config *c.Config) {
-	const phaseName = "teardown/destroy localstack container"
-
-	// Skip if we never setup, or decided to skip destroying localstack
-	if s.Config.SkipSetupLocalStack || s.Config.SkipTearDownLocalStack {
-		s.logPhaseStatus(phaseName, "skipped")
-		return
-	}
-
-	s.logPhaseStatus(phaseName, "started")
-
-	log.WithPrefix(t.Name()).Info("destroying localstack container", "path", config.StateDir)
-	if err := testcontainers.TerminateContainer(s.SetupConfiguration.LocalStackConfiguration.LocalStackContainer); err != nil {
-		log.WithPrefix(t.Name()).Errorf("failed to terminate localstack container: %v", err)
-	}
-
-	s.logPhaseStatus(phaseName, "completed")
+	s.engine.DestroyLocalStack(t, config, s.SetupConfiguration.LocalStackConfiguration)
 }
@@ -9,6 +9,7 @@ import (
 	"dario.cat/mergo"
 	"github.com/cloudposse/test-helpers/pkg/atmos"
 	c "github.com/cloudposse/test-helpers/pkg/atmos/examples-helper/config"
+	"github.com/cloudposse/test-helpers/pkg/atmos/lifecycle"
 	"github.com/gruntwork-io/terratest/modules/aws"
 	"github.com/stretchr/testify/require"
 )
@@ -35,14 +36,18 @@ func GetAtmosOptions(t *testing.T, config *c.Config, componentName string, stack
 		BackendConfig: map[string]interface{}{
 			"workspace_key_prefix": strings.Join([]string{config.RandomIdentifier, stackName}, "-"),
 		},
-		Vars: mergedVars,
+		Vars:     mergedVars,
+		Recorder: config.Recorder,
 		EnvVars: map[string]string{
 			"ATMOS_BASE_PATH":            config.TempDir,
 			"ATMOS_CLI_CONFIG_PATH":      config.TempDir,
 			"COMPONENT_HELPER_STATE_DIR": config.StateDir,
 			"TEST_ACCOUNT_ID":            accountID,
 		},
+		RetryPolicy: atmos.DefaultRetryPolicy(),
 	}
+	lifecycle.SetPluginOptions(config, atmosOptions)
+	lifecycle.SetLocalBackendOptions(config, atmosOptions)
 	return atmosOptions
 }
 
@@ -65,7 +70,8 @@ func getAtmosOptionsFromSetupConfiguration(t *testing.T, config *c.Config, confi
 		BackendConfig: map[string]interface{}{
 			"workspace_key_prefix": strings.Join([]string{config.RandomIdentifier, stackName}, "-"),
 		},
-		Vars: mergedVars,
+		Vars:     mergedVars,
+		Recorder: config.Recorder,
 		EnvVars: map[string]string{
 			"ATMOS_BASE_PATH":            filepath.Join(config.TempDir, configuration.AtmosBaseDir),
 			"ATMOS_CLI_CONFIG_PATH":      filepath.Join(config.TempDir, configuration.AtmosBaseDir),
@@ -73,7 +79,10 @@ func getAtmosOptionsFromSetupConfiguration(t *testing.T, config *c.Config, confi
 		},
 		Targets:            targets,
 		InitRunReconfigure: true,
+		RetryPolicy:        atmos.DefaultRetryPolicy(),
 	}
+	lifecycle.SetPluginOptions(config, atmosOptions)
+	lifecycle.SetLocalBackendOptions(config, atmosOptions)
 	return atmosOptions
 }
 
@@ -98,7 +107,8 @@ func getAtmosOptions(t *testing.T, config *c.Config, s *TestSuite, d *dependency
 		BackendConfig: map[string]interface{}{
 			"workspace_key_prefix": strings.Join([]string{config.RandomIdentifier, d.StackName}, "-"),
 		},
-		Vars: mergedVars,
+		Vars:     mergedVars,
+		Recorder: config.Recorder,
 		EnvVars: map[string]string{
 			"ATMOS_BASE_PATH":            filepath.Join(config.TempDir, s.SetupConfiguration.AtmosBaseDir),
 			"ATMOS_CLI_CONFIG_PATH":      filepath.Join(config.TempDir, s.SetupConfiguration.AtmosBaseDir),
@@ -107,6 +117,9 @@ func getAtmosOptions(t *testing.T, config *c.Config, s *TestSuite, d *dependency
 		Targets:            d.Targets,
 		InitRunReconfigure: true,
 		GenerateBackend:    true,
+		RetryPolicy:        atmos.DefaultRetryPolicy(),
 	}
+	lifecycle.SetPluginOptions(config, atmosOptions)
+	lifecycle.SetLocalBackendOptions(config, atmosOptions)
 	return atmosOptions
 }
@@ -1,18 +1,14 @@
 package dependency
 
-import "github.com/cloudposse/test-helpers/pkg/atmos"
+import (
+	"github.com/cloudposse/test-helpers/pkg/atmos/lifecycle"
+	"github.com/cloudposse/test-helpers/pkg/dag"
+)
 
-type Dependency struct {
-	AdditionalVars     *map[string]interface{}
-	ComponentName      string
-	StackName          string
-	Function           func() error
-	Args               []string
-	Vendor             bool
-	VendorOnly         bool
-	Targets            []string
-	AddRandomAttribute bool
-	Options            *atmos.Options
-	WorkflowName       string
-	WorkflowFile       string
+// Dependency is a component, workflow or function the test suite deploys before its tests run.
+type Dependency = lifecycle.Dependency
+
+// Graph builds the dependency graph of the given dependencies, see lifecycle.Graph.
+func Graph(dependencies []*Dependency) (*dag.Graph, error) {
+	return lifecycle.Graph(dependencies)
 }
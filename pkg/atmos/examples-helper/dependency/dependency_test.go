@@ -0,0 +1,31 @@
+package dependency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphResolvesWorkflowDependenciesByName(t *testing.T) {
+	dependencies := []*Dependency{
+		{ComponentName: "vpc", StackName: "test", DependsOn: []string{"bootstrap"}},
+		{WorkflowName: "bootstrap", WorkflowFile: "bootstrap.yaml"},
+	}
+
+	g, err := Graph(dependencies)
+	require.NoError(t, err)
+
+	order, err := g.TopologicalOrder()
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 0}, order)
+}
+
+func TestGraphFailsOnUnknownDependency(t *testing.T) {
+	dependencies := []*Dependency{
+		{Function: func() error { return nil }, DependsOn: []string{"vpc"}},
+	}
+
+	_, err := Graph(dependencies)
+	require.Error(t, err)
+}
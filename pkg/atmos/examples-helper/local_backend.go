@@ -0,0 +1,31 @@
+package examples_helper
+
+import (
+	"path/filepath"
+	"testing"
+
+	c "github.com/cloudposse/test-helpers/pkg/atmos/examples-helper/config"
+	"github.com/cloudposse/test-helpers/pkg/atmos/lifecycle"
+	"github.com/stretchr/testify/require"
+)
+
+// GenerateLocalBackends writes a local backend override into every component of the atmos project in the temp dir when
+// Config.LocalBackend is set, see lifecycle.Engine.GenerateLocalBackends.
+func (s *TestSuite) GenerateLocalBackends(t *testing.T, config *c.Config) {
+	s.engine.GenerateLocalBackends(t, config, s.componentsDir(config))
+}
+
+// restoreLocalBackend writes the local backend override into the components again after a component was pulled, since
+// a component that is vendored after the setup has none.
+func (s *TestSuite) restoreLocalBackend(t *testing.T, config *c.Config) {
+	if !config.LocalBackend {
+		return
+	}
+	err := lifecycle.WriteLocalBackendOverrides(s.componentsDir(config))
+	require.NoError(t, err)
+}
+
+// componentsDir returns the terraform components directory of the atmos project in the temp dir.
+func (s *TestSuite) componentsDir(config *c.Config) string {
+	return filepath.Join(config.TempDir, s.SetupConfiguration.AtmosBaseDir, "components", "terraform")
+}
@@ -0,0 +1,80 @@
+package examples_helper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudposse/test-helpers/pkg/atmos/lifecycle"
+)
+
+// logPhaseStatus logs the status of a phase with the suite's lifecycle engine.
+func (s *TestSuite) logPhaseStatus(phaseName, status string) {
+	s.engine.LogPhaseStatus(s.T(), phaseName, status)
+}
+
+// BeforePhase registers hooks that run before the phase with the given name, or before every phase for
+// lifecycle.AnyPhase. If a hook fails, the phase fails without running.
+func (s *TestSuite) BeforePhase(phaseName string, hooks ...lifecycle.Hook) {
+	s.engine.Hooks.BeforePhase(phaseName, hooks...)
+}
+
+// AfterPhase registers hooks that run after the phase with the given name completed, e.g. to seed data once the
+// dependencies are deployed.
+func (s *TestSuite) AfterPhase(phaseName string, hooks ...lifecycle.Hook) {
+	s.engine.Hooks.AfterPhase(phaseName, hooks...)
+}
+
+// OnPhaseFailure registers hooks that run when the phase with the given name fails, e.g. to collect diagnostics.
+func (s *TestSuite) OnPhaseFailure(phaseName string, hooks ...lifecycle.Hook) {
+	s.engine.Hooks.OnPhaseFailure(phaseName, hooks...)
+}
+
+// lifecyclePhases returns the setup and teardown phases of the suite. They are composed on first use and then passed to
+// CustomizePhases, if set.
+func (s *TestSuite) lifecyclePhases() *lifecycle.Phases {
+	if s.phases != nil {
+		return s.phases
+	}
+
+	config := s.Config
+	steps := lifecycle.SuiteSteps{
+		ComponentsDir: filepath.Join(s.SetupConfiguration.AtmosBaseDir, "components", "terraform"),
+		Prepare: []lifecycle.Phase{
+			{Name: "setup/bootstrap temp dir", Run: func(t *testing.T) { s.BootstrapTempDir(t, config) }},
+			{Name: "run prehook", Run: func(t *testing.T) { s.CreateTempContents(t, config) }},
+		},
+		DeployDependencies: func(t *testing.T) { s.DeployDependencies(t, config) },
+		DestroyTempDir:     func(t *testing.T) { s.DestroyTempDir(t, config) },
+		DestroyLocalStack:  func(t *testing.T) { s.DestroyLocalStackContainer(t, config) },
+	}
+	if _, err := os.Stat(config.FixturesDir); err == nil {
+		steps.Prepare = append(steps.Prepare, lifecycle.Phase{Name: "fixtures", Run: func(t *testing.T) {
+			s.logPhaseStatus("fixtures", "started")
+			s.engine.CopyFixturesToTempDir(t, config, filepath.Join(config.TempDir, s.SetupConfiguration.AtmosBaseDir))
+			s.logPhaseStatus("fixtures", "completed")
+		}})
+	}
+	steps.Prepare = append(steps.Prepare, lifecycle.Phase{Name: "setup/localstack container", Run: func(t *testing.T) { s.SetupLocalStackContainer(t, config) }})
+	if s.SetupConfiguration.VendorAllComponents {
+		steps.Prepare = append(steps.Prepare, lifecycle.Phase{Name: "vendor components", Run: func(t *testing.T) { s.VendorAllComponents(t, config) }})
+	} else {
+		steps.Prepare = append(steps.Prepare, lifecycle.Phase{Name: "pull dependencies", Run: func(t *testing.T) { s.PullDependencies(t, config) }})
+	}
+	if stack := s.SetupConfiguration.DeployTfStateBackendStack; stack != "" {
+		steps.BeforeDeploy = append(steps.BeforeDeploy, lifecycle.Phase{Name: "init tfstate-backend", Run: func(t *testing.T) { s.InitTerraformState(t, stack) }})
+	}
+	// Skipping the teardown also keeps the dependencies of the examples deployed.
+	if !config.SkipTeardownTestSuite {
+		steps.DestroyDependencies = func(t *testing.T) { s.DestroyDependencies(t, config) }
+	}
+
+	phases := s.engine.SuitePhases(s.T(), config, steps)
+
+	if s.CustomizePhases != nil {
+		s.CustomizePhases(phases)
+	}
+
+	s.phases = phases
+	return s.phases
+}
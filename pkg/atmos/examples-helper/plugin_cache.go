@@ -0,0 +1,20 @@
+package examples_helper
+
+import (
+	"path/filepath"
+	"testing"
+
+	c "github.com/cloudposse/test-helpers/pkg/atmos/examples-helper/config"
+	"github.com/cloudposse/test-helpers/pkg/atmos/lifecycle"
+)
+
+// InitPluginCache creates the shared terraform plugin cache, see lifecycle.InitPluginCache.
+func (s *TestSuite) InitPluginCache() {
+	lifecycle.InitPluginCache(s.T(), s.Config)
+}
+
+// PrewarmPluginCache downloads the providers of the components in the temp dir into the plugin cache, see
+// lifecycle.Engine.PrewarmPluginCache.
+func (s *TestSuite) PrewarmPluginCache(t *testing.T, config *c.Config) {
+	s.engine.PrewarmPluginCache(t, config, filepath.Join(config.TempDir, s.SetupConfiguration.AtmosBaseDir, "components", "terraform"))
+}
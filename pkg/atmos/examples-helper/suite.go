@@ -9,7 +9,6 @@ import (
 	gwaws "github.com/gruntwork-io/terratest/modules/aws"
 	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/shell"
-	"os"
 	"os/exec"
 	"path/filepath"
 	"testing"
@@ -18,6 +17,8 @@ import (
 	"github.com/cloudposse/test-helpers/pkg/atmos"
 	c "github.com/cloudposse/test-helpers/pkg/atmos/examples-helper/config"
 	"github.com/cloudposse/test-helpers/pkg/atmos/examples-helper/dependency"
+	"github.com/cloudposse/test-helpers/pkg/atmos/lifecycle"
+	"github.com/cloudposse/test-helpers/pkg/atmos/report"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -47,10 +48,18 @@ type TestSuite struct {
 	Config       *c.Config
 	Dependencies []*dependency.Dependency
 	Deployments  []*atmos.Options
+	Report       *report.Reporter
 	suite.Suite
 	SetupConfiguration *SetupConfiguration
 	SuperUserAccessKey string
 	SuperUserSecretKey string
+
+	// CustomizePhases is called with the setup and teardown phases of the suite before they run, to add, replace or
+	// remove phases.
+	CustomizePhases func(phases *lifecycle.Phases)
+
+	engine lifecycle.Engine
+	phases *lifecycle.Phases
 }
 
 type TestingSuite interface {
@@ -94,7 +103,10 @@ func (s *TestSuite) AddComponentDependencyTargets(t *testing.T, componentName st
 		AddRandomAttribute: addRandomAttribute,
 	})
 }
-func (s *TestSuite) AddComponentDependency(t *testing.T, componentName string, stackName string, additionalVars *map[string]interface{}) {
+
+// AddComponentDependency registers a component that is vendored and deployed before the tests run. dependsOn lists
+// the names of the other dependencies that must be deployed before this one.
+func (s *TestSuite) AddComponentDependency(t *testing.T, componentName string, stackName string, additionalVars *map[string]interface{}, dependsOn ...string) {
 	s.Dependencies = append(s.Dependencies, &dependency.Dependency{
 		AdditionalVars:     additionalVars,
 		ComponentName:      componentName,
@@ -102,6 +114,7 @@ func (s *TestSuite) AddComponentDependency(t *testing.T, componentName string, s
 		Vendor:             true,
 		Targets:            nil,
 		AddRandomAttribute: false,
+		DependsOn:          dependsOn,
 	})
 }
 
@@ -157,6 +170,7 @@ func (s *TestSuite) DeployAtmosComponentWithOptions(t *testing.T, options *atmos
 
 	atmosOptions := getAtmosOptionsFromSetupConfiguration(t, s.Config, s.SetupConfiguration, componentName, stackName, &mergedVars, nil)
 	atmosOptions.MergeOptions(options)
+	s.engine.AddDiagnosticsTarget(atmosOptions)
 	output, err := atmos.ApplyE(t, atmosOptions)
 	if err != nil {
 		s.logPhaseStatus(phaseName, "failed")
@@ -184,6 +198,7 @@ func (s *TestSuite) DeployAtmosComponent(t *testing.T, componentName string, sta
 
 	mergedVars := s.getMergedVars(t, additionalVars)
 	atmosOptions := getAtmosOptionsFromSetupConfiguration(t, s.Config, s.SetupConfiguration, componentName, stackName, &mergedVars, nil)
+	s.engine.AddDiagnosticsTarget(atmosOptions)
 
 	output, err := atmos.ApplyE(t, atmosOptions)
 	if err != nil {
@@ -206,6 +221,11 @@ func (s *TestSuite) DestroyAtmosComponent(t *testing.T, componentName string, st
 
 	s.logPhaseStatus(phaseName, "started")
 
+	// The component of a failed test is about to be destroyed, so its diagnostics are collected first.
+	if t.Failed() {
+		s.engine.CollectDiagnostics(t, s.Config)
+	}
+
 	mergedVars := s.getMergedVars(t, additionalVars)
 	atmosOptions := getAtmosOptionsFromSetupConfiguration(t, s.Config, s.SetupConfiguration, componentName, stackName, &mergedVars, nil)
 
@@ -233,7 +253,9 @@ func (s *TestSuite) SetupSuite() {
 		panic("SetupSuite called with nil *testing.T, call s.SetT(t) first")
 	}
 
-	config := s.Config
+	s.InitReport()
+	s.InitDiagnostics()
+	s.InitPluginCache()
 
 	if s.Config.SkipSetupTestSuite {
 		s.logPhaseStatus("setup", "skipped")
@@ -241,25 +263,8 @@ func (s *TestSuite) SetupSuite() {
 		s.logPhaseStatus("setup", "started")
 	}
 
-	s.BootstrapTempDir(t, config)
-	s.CreateTempContents(t, config)
-	if _, err := os.Stat(config.FixturesDir); err == nil {
-		s.logPhaseStatus("fixtures", "started")
-		s.copyDirectoryRecursively(t, config.FixturesDir, filepath.Join(config.TempDir, s.SetupConfiguration.AtmosBaseDir))
-		s.logPhaseStatus("fixtures", "completed")
-	}
-	s.SetupLocalStackContainer(t, config)
-	if s.SetupConfiguration.VendorAllComponents {
-		s.VendorAllComponents(t, config)
-	} else {
-		s.PullDependencies(t, config)
-	}
-
-	if s.SetupConfiguration.DeployTfStateBackendStack != "" {
-		s.InitTerraformState(t, s.SetupConfiguration.DeployTfStateBackendStack)
-	}
-
-	s.DeployDependencies(t, config)
+	// Every phase runs as a subtest, so that a failure is reported against the phase it happened in.
+	s.engine.RunSetup(t, s.lifecyclePhases().Setup)
 
 	s.logPhaseStatus("setup", "completed")
 }
@@ -267,19 +272,16 @@ func (s *TestSuite) SetupSuite() {
 func (s *TestSuite) TearDownSuite() {
 
 	t := s.T()
-	if !s.Config.SkipTearDownLocalStack {
-		defer s.DestroyLocalStackContainer(t, s.Config)
-	}
-	if s.Config.SkipTeardownTestSuite {
-		s.logPhaseStatus("teardown", "skipped")
-		return
-	}
 
-	defer s.DestroyTempDir(t, s.Config)
-	defer s.DestroyConfigFile(t, s.Config)
+	// The report is written last, so that it includes every teardown phase.
+	defer s.WriteReport(t)
+
+	// Teardown phases run even when an earlier one failed, so that as much as possible is cleaned up. A failed phase
+	// still fails the suite.
+	s.engine.RunTeardown(t, s.lifecyclePhases().Teardown)
 
-	if !s.Config.SkipDestroyDependencies {
-		s.DestroyDependencies(t, s.Config)
+	if s.Config.SkipTeardownTestSuite {
+		s.logPhaseStatus("teardown", "skipped")
 	}
 }
 
@@ -293,11 +295,13 @@ func (s *TestSuite) RunAtmosWorkflow(t *testing.T, WorkflowName string, Workflow
 		AtmosBasePath:   AtmosBasePath,
 		NoColor:         true,
 		GenerateBackend: true,
+		Recorder:        s.Config.Recorder,
 		EnvVars: map[string]string{
 			"ATMOS_BASE_PATH":            AtmosBasePath,
 			"ATMOS_CLI_CONFIG_PATH":      AtmosBasePath,
 			"COMPONENT_HELPER_STATE_DIR": s.Config.StateDir,
 		},
+		RetryPolicy: atmos.DefaultRetryPolicy(),
 	}
 
 	_, err := atmos.WorkflowE(t, atmosOptions, WorkflowName, WorkflowFile)
@@ -0,0 +1,39 @@
+package atmos
+
+import (
+	"context"
+
+	tt "github.com/cloudposse/test-helpers/pkg/testing"
+	"github.com/gruntwork-io/terratest/modules/shell"
+)
+
+// CommandOutput is the output of a command run by an Executor.
+type CommandOutput struct {
+	Stdout   string // The lines the command wrote to stdout
+	Stderr   string // The lines the command wrote to stderr
+	Combined string // The lines of stdout and stderr in the order they were written
+}
+
+// Executor runs the atmos commands built by the functions in this package. When a command exits with a non-zero exit
+// code, Execute returns an error that has an ExitCode() int method somewhere in its chain, like *exec.ExitError, so that
+// the exit code can be determined. The output of the command is returned even if it fails.
+type Executor interface {
+	Execute(ctx context.Context, t tt.TestingT, command shell.Command) (CommandOutput, error)
+}
+
+// ShellExecutor runs commands as child processes in a process group of their own, which is killed when the context is
+// done. It is used when Options.Executor is not set.
+type ShellExecutor struct{}
+
+// Execute runs the command, logging every line of its output. A CommandFailed error is returned if the command fails.
+func (ShellExecutor) Execute(ctx context.Context, t tt.TestingT, command shell.Command) (CommandOutput, error) {
+	output, err := runCommand(ctx, t, command)
+	return output.result(), err
+}
+
+func executorFor(options *Options) Executor {
+	if options.Executor == nil {
+		return ShellExecutor{}
+	}
+	return options.Executor
+}
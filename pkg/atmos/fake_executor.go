@@ -0,0 +1,243 @@
+package atmos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	tt "github.com/cloudposse/test-helpers/pkg/testing"
+	"github.com/gruntwork-io/terratest/modules/shell"
+)
+
+// FakeCall is a command that was run by a FakeExecutor.
+type FakeCall struct {
+	Binary     string            // The binary the command would have run
+	Args       []string          // The arguments of the command
+	WorkingDir string            // The directory the command would have run in
+	Env        map[string]string // The environment variables set for the command, on top of those of the test process
+}
+
+// FakeResponse is the canned result a FakeExecutor replays for the commands it matches.
+type FakeResponse struct {
+	Args     []string `json:"args,omitempty"`      // The arguments a command must start with to be answered by this response, every command if empty
+	Stdout   string   `json:"stdout,omitempty"`    // The stdout of the command
+	Stderr   string   `json:"stderr,omitempty"`    // The stderr of the command
+	Combined string   `json:"combined,omitempty"`  // The interleaved stdout and stderr of the command, Stdout followed by Stderr if empty
+	ExitCode int      `json:"exit_code,omitempty"` // The exit code of the command, which fails if it is not zero
+	Error    string   `json:"error,omitempty"`     // An error running the command, other than a non-zero exit code
+}
+
+func (r FakeResponse) matches(args []string) bool {
+	if len(r.Args) > len(args) {
+		return false
+	}
+	for i, arg := range r.Args {
+		if args[i] != arg {
+			return false
+		}
+	}
+	return true
+}
+
+func (r FakeResponse) output() CommandOutput {
+	output := CommandOutput{Stdout: r.Stdout, Stderr: r.Stderr, Combined: r.Combined}
+	if output.Combined == "" {
+		var lines []string
+		for _, s := range []string{r.Stdout, r.Stderr} {
+			if s != "" {
+				lines = append(lines, s)
+			}
+		}
+		output.Combined = strings.Join(lines, "\n")
+	}
+	return output
+}
+
+func (r FakeResponse) err() error {
+	var err error
+	switch {
+	case r.Error != "":
+		err = errors.New(r.Error)
+	case r.ExitCode != DefaultSuccessExitCode:
+		err = FakeExitError(r.ExitCode)
+	default:
+		return nil
+	}
+	return &CommandFailed{Underlying: err, Stdout: r.Stdout, Stderr: r.Stderr}
+}
+
+// FakeExitError is returned by a FakeExecutor for responses with a non-zero exit code.
+type FakeExitError int
+
+func (err FakeExitError) Error() string {
+	return fmt.Sprintf("exit status %d", int(err))
+}
+
+// ExitCode returns the exit code of the response.
+func (err FakeExitError) ExitCode() int {
+	return int(err)
+}
+
+// FakeExecutor is an Executor that records the commands it is asked to run and replays canned responses instead of
+// running them, so that the functions in this package can be tested without atmos.
+//
+// A command is answered by the first response it matches that has not been replayed yet. Once all the responses a
+// command matches have been replayed, the last one is replayed again. Commands that match no response fail with a
+// NoFakeResponse error, unless Next is set, in which case they are run by Next and its output is added as a response.
+// Saving the responses recorded that way with Save, and loading them with LoadFakeExecutor, replays a real run.
+type FakeExecutor struct {
+	Next Executor // Runs the commands that match no response, recording their output. Commands are not run if nil.
+
+	mu        sync.Mutex
+	responses []FakeResponse
+	replayed  []bool
+	calls     []FakeCall
+}
+
+// NewFakeExecutor creates a FakeExecutor that replays the given responses.
+func NewFakeExecutor(responses ...FakeResponse) *FakeExecutor {
+	executor := &FakeExecutor{}
+	executor.Respond(responses...)
+	return executor
+}
+
+// LoadFakeExecutor creates a FakeExecutor that replays the responses saved to path by FakeExecutor.Save.
+func LoadFakeExecutor(path string) (*FakeExecutor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []FakeResponse
+	if err := json.Unmarshal(data, &responses); err != nil {
+		return nil, fmt.Errorf("parsing fake responses %s: %w", path, err)
+	}
+	return NewFakeExecutor(responses...), nil
+}
+
+// Respond adds responses to replay, after those that were added before.
+func (e *FakeExecutor) Respond(responses ...FakeResponse) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.responses = append(e.responses, responses...)
+	e.replayed = append(e.replayed, make([]bool, len(responses))...)
+}
+
+// Execute records the command and returns the output of the response it matches.
+func (e *FakeExecutor) Execute(ctx context.Context, t tt.TestingT, command shell.Command) (CommandOutput, error) {
+	command.Logger.Logf(t, "Running command %s with args %s", command.Command, command.Args)
+	e.record(command)
+
+	if err := ctx.Err(); err != nil {
+		return CommandOutput{}, err
+	}
+
+	response, ok := e.response(command.Args)
+	if ok {
+		return response.output(), response.err()
+	}
+	if e.Next == nil {
+		return CommandOutput{}, NoFakeResponse{Args: command.Args}
+	}
+
+	output, err := e.Next.Execute(ctx, t, command)
+	if ctx.Err() == nil {
+		e.Respond(recordedResponse(command, output, err))
+	}
+	return output, err
+}
+
+func (e *FakeExecutor) record(command shell.Command) {
+	call := FakeCall{
+		Binary:     command.Command,
+		Args:       append([]string(nil), command.Args...),
+		WorkingDir: command.WorkingDir,
+		Env:        map[string]string{},
+	}
+	for key, value := range command.Env {
+		call.Env[key] = value
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.calls = append(e.calls, call)
+}
+
+// response returns the response to replay for a command with the given arguments.
+func (e *FakeExecutor) response(args []string) (FakeResponse, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	last := -1
+	for i, response := range e.responses {
+		if !response.matches(args) {
+			continue
+		}
+		if !e.replayed[i] {
+			e.replayed[i] = true
+			return response, true
+		}
+		last = i
+	}
+	if last < 0 {
+		return FakeResponse{}, false
+	}
+	return e.responses[last], true
+}
+
+func recordedResponse(command shell.Command, output CommandOutput, err error) FakeResponse {
+	response := FakeResponse{
+		Args:     append([]string(nil), command.Args...),
+		Stdout:   output.Stdout,
+		Stderr:   output.Stderr,
+		Combined: output.Combined,
+	}
+	exitCode, exitCodeErr := exitCodeForError(err)
+	if exitCodeErr != nil {
+		response.Error = exitCodeErr.Error()
+	}
+	response.ExitCode = exitCode
+	return response
+}
+
+// Calls returns the commands the executor was asked to run, in the order they were run.
+func (e *FakeExecutor) Calls() []FakeCall {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]FakeCall(nil), e.calls...)
+}
+
+// LastCall returns the last command the executor was asked to run. It returns false if no command was run.
+func (e *FakeExecutor) LastCall() (FakeCall, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.calls) == 0 {
+		return FakeCall{}, false
+	}
+	return e.calls[len(e.calls)-1], true
+}
+
+// Responses returns the responses of the executor, including those recorded from Next.
+func (e *FakeExecutor) Responses() []FakeResponse {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]FakeResponse(nil), e.responses...)
+}
+
+// Save writes the responses of the executor to path as JSON, to be replayed with LoadFakeExecutor.
+func (e *FakeExecutor) Save(path string) error {
+	data, err := json.MarshalIndent(e.Responses(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
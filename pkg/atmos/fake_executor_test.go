@@ -0,0 +1,125 @@
+package atmos
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyEWithFakeExecutor(t *testing.T) {
+	executor := NewFakeExecutor(FakeResponse{Stdout: "Apply complete!"})
+	options := &Options{
+		AtmosBasePath: "/atmos",
+		Component:     "vpc",
+		Stack:         "default-test",
+		Vars:          map[string]interface{}{"enabled": true},
+		EnvVars:       map[string]string{"ATMOS_CLI_CONFIG_PATH": "/atmos"},
+		Executor:      executor,
+	}
+
+	out, err := ApplyE(t, options)
+	require.NoError(t, err)
+	assert.Equal(t, "Apply complete!", out)
+
+	call, ok := executor.LastCall()
+	require.True(t, ok)
+	assert.Equal(t, AtmosDefaultPath, call.Binary)
+	assert.Equal(t, []string{"terraform", "apply", "vpc", "-s", "default-test", "-input=false", "-auto-approve", "-var", "enabled=true", "--auto-generate-backend-file=false", "--init-run-reconfigure=false", "-lock=false"}, call.Args)
+	assert.Equal(t, "/atmos", call.WorkingDir)
+	assert.Equal(t, map[string]string{"ATMOS_CLI_CONFIG_PATH": "/atmos"}, call.Env)
+}
+
+func TestOutputAllEWithFakeExecutor(t *testing.T) {
+	executor := NewFakeExecutor(FakeResponse{
+		Args:   []string{"terraform", "output"},
+		Stdout: `{"vpc_id": {"value": "vpc-123"}, "subnet_ids": {"value": ["subnet-1", "subnet-2"]}}`,
+	})
+	options := &Options{Component: "vpc", Stack: "default-test", Executor: executor}
+
+	outputs, err := OutputAllE(t, options)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"vpc_id":     "vpc-123",
+		"subnet_ids": []interface{}{"subnet-1", "subnet-2"},
+	}, outputs)
+	require.Len(t, executor.Calls(), 1)
+}
+
+func TestVendorPullEWithFakeExecutor(t *testing.T) {
+	executor := NewFakeExecutor(FakeResponse{Args: []string{"vendor", "pull"}})
+	options := &Options{VendorComponent: "vpc", VendorTags: []string{"networking"}, Executor: executor}
+
+	_, err := VendorPullE(t, options)
+	require.NoError(t, err)
+
+	call, ok := executor.LastCall()
+	require.True(t, ok)
+	assert.Equal(t, "vendor", call.Args[0])
+	assert.Equal(t, "pull", call.Args[1])
+	assert.Contains(t, call.Args, "vpc")
+	assert.Contains(t, call.Args, "networking")
+}
+
+func TestFakeExecutorReplaysResponsesInOrder(t *testing.T) {
+	executor := NewFakeExecutor(
+		FakeResponse{Args: []string{"terraform", "plan"}, Stderr: "Error: registry service is unreachable", ExitCode: 1},
+		FakeResponse{Args: []string{"terraform", "plan"}, Stdout: "No changes."},
+	)
+	options := &Options{
+		Executor:             executor,
+		RetryableAtmosErrors: map[string]string{".*registry service is unreachable.*": "transient"},
+		MaxRetries:           1,
+	}
+
+	out, err := RunAtmosCommandE(t, options, "terraform", "plan", "vpc")
+	require.NoError(t, err)
+	assert.Equal(t, "No changes.", out)
+
+	// The last matching response is replayed once the others have been.
+	out, err = RunAtmosCommandE(t, options, "terraform", "plan", "vpc")
+	require.NoError(t, err)
+	assert.Equal(t, "No changes.", out)
+	assert.Len(t, executor.Calls(), 3)
+
+	_, err = RunAtmosCommandE(t, options, "terraform", "apply", "vpc")
+	var noResponse NoFakeResponse
+	require.True(t, errors.As(err, &noResponse))
+	assert.Equal(t, []string{"terraform", "apply", "vpc"}, noResponse.Args)
+}
+
+func TestFakeExecutorExitCode(t *testing.T) {
+	options := &Options{Executor: NewFakeExecutor(FakeResponse{Args: []string{"terraform", "plan"}, ExitCode: 2})}
+
+	exitCode, err := GetExitCodeForAtmosCommandE(t, options, "terraform", "plan", "vpc")
+	require.NoError(t, err)
+	assert.Equal(t, 2, exitCode)
+}
+
+func TestFakeExecutorRecordsAndReplays(t *testing.T) {
+	recorder := &FakeExecutor{Next: ShellExecutor{}}
+	options := &Options{AtmosBinary: "sh", AtmosBasePath: t.TempDir(), Executor: recorder}
+
+	out, err := RunAtmosCommandE(t, options, "-c", "echo recorded")
+	require.NoError(t, err)
+	assert.Equal(t, "recorded", out)
+	exitCode, err := GetExitCodeForAtmosCommandE(t, options, "-c", "exit 3")
+	require.NoError(t, err)
+	assert.Equal(t, 3, exitCode)
+
+	path := filepath.Join(t.TempDir(), "responses.json")
+	require.NoError(t, recorder.Save(path))
+
+	replayer, err := LoadFakeExecutor(path)
+	require.NoError(t, err)
+	options = &Options{AtmosBinary: "does-not-exist", Executor: replayer}
+
+	out, err = RunAtmosCommandE(t, options, "-c", "echo recorded")
+	require.NoError(t, err)
+	assert.Equal(t, "recorded", out)
+	exitCode, err = GetExitCodeForAtmosCommandE(t, options, "-c", "exit 3")
+	require.NoError(t, err)
+	assert.Equal(t, 3, exitCode)
+}
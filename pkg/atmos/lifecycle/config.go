@@ -0,0 +1,295 @@
+package lifecycle
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cloudposse/test-helpers/pkg/atmos"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	flag.String("component-dest-dir", "", "The path to the component destination directory, relative to the temp directory")
+	flag.String("config", "test_suite.yaml", "The path to the config file")
+	flag.Int("dependency-parallelism", 1, "The maximum number of dependencies to deploy or destroy concurrently")
+	flag.String("diagnostics-dir", "", "The directory to write a diagnostics bundle to when a phase or test fails, no bundles are collected if empty")
+	flag.String("fixtures-dir", "fixtures", "The path to the fixtures directory")
+	flag.Bool("local-backend", true, "Generates a local backend for every component, keeping its state under the state directory")
+	flag.Bool("only-deploy-dependencies", true, "Only run the deploy dependencies phase of tests")
+	flag.String("run-mode", "local", "Run mode for the test suite (local, gha)")
+	flag.String("plugin-cache-dir", "", "The path to the terraform plugin cache shared by test suites, defaults to a directory in the user cache dir")
+	flag.String("plugin-dir", "", "The path to a local terraform provider mirror to install providers from instead of downloading them")
+	flag.Bool("skip-deploy-component", true, "Disables running the deploy component phase of tests")
+	flag.Bool("skip-deploy-dependencies", true, "Disables running the deploy dependencies phase of tests")
+	flag.Bool("skip-destroy-component", true, "Disables running the destroy component phase of tests")
+	flag.Bool("skip-destroy-dependencies", true, "Disables running the destroy dependencies phase of tests")
+	flag.Bool("skip-enabled-flag-test", true, "Disables running the Enabled flag test")
+	flag.Bool("skip-plugin-cache", true, "Disables the shared terraform plugin cache")
+	flag.Bool("skip-setup", true, "Disables running the setup test suite phase of tests")
+	flag.Bool("skip-setup-localstack", true, "Disables running the setup localstack phase of tests")
+	flag.Bool("skip-temp-contents", true, "Disables running the temp contents phase of tests")
+	flag.Bool("skip-teardown", true, "Disables running the teardown test suite phase of tests")
+	flag.Bool("skip-teardown-localstack", true, "Disables running the teardown test suite phase of tests")
+	flag.Bool("skip-upgrade-test", true, "Disables running the upgrade test")
+	flag.Bool("skip-vendor", true, "Disables running the vendor dependencies phase of tests")
+	flag.String("report-dir", "", "The directory to write the JUnit XML and JSON run reports to, no reports are written if empty")
+	flag.String("src-dir", "", "The path to the component source directory")
+	flag.String("state-dir", "", "The path to the terraform state directory")
+	flag.String("temp-dir", "", "The path to the temp directory")
+	flag.String("upgrade-from-version", "", "The released version of the component to upgrade from, defaults to the latest tag")
+	flag.String("upgrade-source", "", "The vendor source of the released component, with {{.Version}} in place of the version")
+	flag.Bool("validate-stacks", true, "Runs atmos validate stacks before deploying dependencies")
+}
+
+// Config configures a test suite. It is read from the config file, test_suite.yaml by default, and the command line
+// flags, and written back to the config file so that a later run can reuse the temp dir of this one.
+type Config struct {
+	ComponentDestDir        string
+	ConfigFilePath          string
+	DependencyParallelism   int
+	DiagnosticsDir          string
+	FixturesDir             string
+	LocalBackend            bool
+	PluginCacheDir          string
+	PluginDir               string
+	RandomIdentifier        string
+	ReportDir               string
+	RunMode                 string
+	OnlyDeployDependencies  bool
+	SkipDeployComponent     bool
+	SkipDeployDependencies  bool
+	SkipDestroyComponent    bool
+	SkipDestroyDependencies bool
+	SkipEnabledFlagTest     bool
+	SkipPluginCache         bool
+	SkipSetupLocalStack     bool
+	SkipSetupTestSuite      bool
+	SkipTempContents        bool
+	SkipTeardownTestSuite   bool
+	SkipTearDownLocalStack  bool
+	SkipUpgradeTest         bool
+	SkipVendorDependencies  bool
+	SrcDir                  string
+	StateDir                string
+	TempDir                 string
+
+	// TemplateValues are user-supplied values that fixture templates are rendered with, as .Values. They are read from
+	// the config file only.
+	TemplateValues map[string]interface{}
+
+	// Recorder receives every atmos command run with options built from this config. It is set by the test suite and
+	// is not read from the config file.
+	Recorder           atmos.CommandRecorder `mapstructure:"-"`
+	UpgradeFromVersion string
+	UpgradeSource      string
+	ValidateStacks     bool
+}
+
+func (c *Config) WriteConfig() error {
+	return writeConfigWithoutPFlags(c.ConfigFilePath)
+}
+
+func writeConfigWithoutPFlags(filename string) error {
+	// Create a temporary viper instance
+	tempViper := viper.New()
+
+	// Get all settings
+	allSettings := viper.AllSettings()
+
+	// Copy settings to tempViper, skipping pflag-bound keys
+	for key, value := range allSettings {
+		if !isPFlagBound(key) {
+			tempViper.Set(key, value)
+		}
+	}
+
+	// Write the filtered configuration to the file
+	return tempViper.WriteConfigAs(filename)
+}
+
+// isPFlagBound checks if a key is bound to a pflag
+func isPFlagBound(key string) bool {
+	if key == "configfilepath" {
+		return false
+	}
+
+	// Check if the key corresponds to a defined flag
+	return strings.HasPrefix(key, "skip") ||
+		strings.HasPrefix(key, "test") ||
+		strings.HasPrefix(key, "only") ||
+		flag.Lookup(key) != nil
+}
+
+func InitConfig(t *testing.T) *Config {
+	viper.SetConfigName("test_suite")
+	viper.SetConfigType("yaml")
+	viper.AddConfigPath(".")
+
+	viper.SetDefault("ConfigFilePath", "test_suite.yaml")
+	viper.SetDefault("FixturesDir", "fixtures")
+	viper.SetDefault("ComponentDestDir", "")
+	viper.SetDefault("DependencyParallelism", 1)
+	viper.SetDefault("DiagnosticsDir", "")
+	viper.SetDefault("LocalBackend", false)
+
+	randID := random.UniqueId()
+	viper.SetDefault("RandomIdentifier", strings.ToLower(randID))
+	viper.SetDefault("ReportDir", "")
+	viper.SetDefault("PluginCacheDir", "")
+	viper.SetDefault("PluginDir", "")
+	viper.SetDefault("RunMode", "local")
+
+	viper.SetDefault("OnlyDeployDependencies", false)
+	viper.SetDefault("SkipDeployComponent", false)
+	viper.SetDefault("SkipDeployDependencies", false)
+	viper.SetDefault("SkipDestroyComponent", false)
+	viper.SetDefault("SkipDestroyDependencies", false)
+	viper.SetDefault("SkipEnabledFlagTest", false)
+	viper.SetDefault("SkipPluginCache", false)
+	viper.SetDefault("SkipSetupLocalStack", false)
+	viper.SetDefault("SkipSetupTestSuite", false)
+	viper.SetDefault("SkipTeardownTestSuite", false)
+	viper.SetDefault("SkipTearDownLocalStack", false)
+	viper.SetDefault("SkipTempContents", false)
+	viper.SetDefault("SkipUpgradeTest", false)
+	viper.SetDefault("SkipVendorDependencies", false)
+	viper.SetDefault("TempDir", "")
+	viper.SetDefault("SrcDir", "../src")
+	viper.SetDefault("StateDir", "")
+	viper.SetDefault("UpgradeFromVersion", "")
+	viper.SetDefault("UpgradeSource", "")
+	viper.SetDefault("ValidateStacks", false)
+
+	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
+	pflag.Parse()
+
+	err := viper.BindPFlags(pflag.CommandLine)
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("ComponentDestDir", pflag.Lookup("component-dest-dir"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("ConfigFilePath", pflag.Lookup("config"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("RunMode", pflag.Lookup("run-mode"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("DependencyParallelism", pflag.Lookup("dependency-parallelism"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("DiagnosticsDir", pflag.Lookup("diagnostics-dir"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("FixturesDir", pflag.Lookup("fixtures-dir"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("LocalBackend", pflag.Lookup("local-backend"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("OnlyDeployDependencies", pflag.Lookup("only-deploy-dependencies"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("SkipEnabledFlagTest", pflag.Lookup("skip-enabled-flag-test"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("SkipPluginCache", pflag.Lookup("skip-plugin-cache"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("SkipDeployComponent", pflag.Lookup("skip-deploy-component"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("SkipDeployDependencies", pflag.Lookup("skip-deploy-dependencies"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("SkipDestroyComponent", pflag.Lookup("skip-destroy-component"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("SkipDestroyDependencies", pflag.Lookup("skip-destroy-dependencies"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("SkipSetupLocalStack", pflag.Lookup("skip-setup-localstack"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("SkipSetupTestSuite", pflag.Lookup("skip-setup"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("SkipTeardownTestSuite", pflag.Lookup("skip-teardown"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("SkipTearDownLocalStack", pflag.Lookup("skip-teardown-localstack"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("SkipTempContents", pflag.Lookup("skip-temp-contents"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("ReportDir", pflag.Lookup("report-dir"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("PluginCacheDir", pflag.Lookup("plugin-cache-dir"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("PluginDir", pflag.Lookup("plugin-dir"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("StateDir", pflag.Lookup("state-dir"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("SrcDir", pflag.Lookup("src-dir"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("TempDir", pflag.Lookup("temp-dir"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("SkipVendorDependencies", pflag.Lookup("skip-vendor"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("SkipUpgradeTest", pflag.Lookup("skip-upgrade-test"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("UpgradeFromVersion", pflag.Lookup("upgrade-from-version"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("UpgradeSource", pflag.Lookup("upgrade-source"))
+	require.NoError(t, err)
+
+	err = viper.BindPFlag("ValidateStacks", pflag.Lookup("validate-stacks"))
+	require.NoError(t, err)
+
+	if err := viper.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+			// Config file not found; ignore error and use defaults
+		} else {
+			t.Fatal(fmt.Errorf("fatal error config file: %w", err))
+		}
+	}
+
+	config := &Config{}
+	err = viper.Unmarshal(config)
+	if err != nil {
+		t.Fatal(fmt.Errorf("fatal error unmarshalling config: %w", err))
+	}
+
+	err = writeConfigWithoutPFlags(viper.GetString("ConfigFilePath"))
+	require.NoError(t, err)
+
+	return config
+}
+
+// AnyPhasesSkipped returns true if any phase of the suite is skipped. The temp dir and the config file are then kept,
+// since a later run may need them.
+func (c *Config) AnyPhasesSkipped() bool {
+	return c.OnlyDeployDependencies ||
+		c.SkipDeployComponent ||
+		c.SkipDeployDependencies ||
+		c.SkipDestroyComponent ||
+		c.SkipDestroyDependencies ||
+		c.SkipSetupTestSuite ||
+		c.SkipTeardownTestSuite ||
+		c.SkipVendorDependencies
+}
@@ -0,0 +1,118 @@
+package lifecycle
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	log "github.com/charmbracelet/log"
+	"github.com/cloudposse/test-helpers/pkg/atmos"
+)
+
+// componentLocks serializes atmos commands that run against the same component directory, since terraform keeps its
+// working state (.terraform, selected workspace) there.
+type componentLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func (l *componentLocks) lock(componentName string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*sync.Mutex{}
+	}
+	m, ok := l.locks[componentName]
+	if !ok {
+		m = &sync.Mutex{}
+		l.locks[componentName] = m
+	}
+	l.mu.Unlock()
+
+	m.Lock()
+	return m.Unlock
+}
+
+// WalkDependencies runs fn for every dependency in its own subtest of the phase, so that a failure is reported against
+// the dependency. Dependencies run in topological order of their DependsOn, or in reverse topological order if reverse
+// is set, so that nothing is destroyed while a dependent still exists. Up to parallelism dependencies run at the same
+// time, but never two of the same component. The walk stops scheduling dependencies after the first error, which is
+// returned.
+func (e *Engine) WalkDependencies(t *testing.T, phaseName string, dependencies []*Dependency, parallelism int, reverse bool, fn func(t *testing.T, i int) error) error {
+	graph, err := Graph(dependencies)
+	if err != nil {
+		return err
+	}
+
+	walk := graph.Walk
+	if reverse {
+		walk = graph.ReverseWalk
+	}
+
+	locks := &componentLocks{}
+	return walk(parallelism, func(i int) error {
+		dependency := dependencies[i]
+		return e.RunDependency(t, phaseName, dependency.TestName(i), func(t *testing.T) error {
+			defer locks.lock(dependency.ComponentName)()
+
+			return fn(t, i)
+		})
+	})
+}
+
+// DeployDependency deploys a dependency with the given atmos options: it runs its function or its workflow, or applies
+// its component. Vendor only dependencies are skipped.
+func (e *Engine) DeployDependency(t *testing.T, dependency *Dependency, atmosOptions *atmos.Options) error {
+	if dependency.VendorOnly {
+		log.WithPrefix(t.Name()).Info("skipping vendor only dependency", "component", dependency.ComponentName)
+		t.Skip("vendor only dependency")
+	}
+
+	if dependency.Function != nil {
+		e.LogPhaseStatus(t, "deploy dependencies/function", "started")
+		err := dependency.Function()
+		if err != nil {
+			log.WithPrefix(t.Name()+" deploy function dependency").Error("failed to run function", "error", err)
+		}
+
+		e.LogPhaseStatus(t, "deploy dependencies/function", "completed")
+		return nil
+	}
+
+	if dependency.WorkflowFile != "" || dependency.WorkflowName != "" {
+		if dependency.WorkflowFile == "" || dependency.WorkflowName == "" {
+			log.WithPrefix(t.Name()).Info("skipping Workflow Dependency - Missing WorkflowName or WorkflowFile", "WorkflowName", dependency.WorkflowName, "WorkflowFile", dependency.WorkflowFile)
+			t.Skip("missing WorkflowName or WorkflowFile")
+		}
+		e.LogPhaseStatus(t, "deploy dependencies/workflow "+dependency.WorkflowName+" -f "+dependency.WorkflowFile, "started")
+		output, err := atmos.WorkflowE(t, atmosOptions, dependency.WorkflowName, dependency.WorkflowFile)
+		if err != nil {
+			log.WithPrefix(t.Name()).Error("failed to run workflow", "WorkflowName", dependency.WorkflowName, "WorkflowFile", dependency.WorkflowFile, "error", err)
+			return err
+		}
+		log.WithPrefix(t.Name()).WithPrefix(fmt.Sprintf("Workflow [%s -f %s]", dependency.WorkflowName, dependency.WorkflowFile)).Info(output)
+		e.LogPhaseStatus(t, "deploy dependencies/workflow "+dependency.WorkflowName+" -f "+dependency.WorkflowFile, "completed")
+		return nil
+	}
+
+	log.WithPrefix(t.Name()).Info("deploying dependency", "component", dependency.ComponentName, "stack", dependency.StackName)
+	_, err := atmos.ApplyE(t, atmosOptions)
+	return err
+}
+
+// DestroyDependency destroys the component of a dependency with the given atmos options. Function, workflow and vendor
+// only dependencies are skipped, since there is nothing to destroy.
+func (e *Engine) DestroyDependency(t *testing.T, dependency *Dependency, atmosOptions *atmos.Options) error {
+	if dependency.VendorOnly {
+		log.WithPrefix(t.Name()).Info("skipping vendor only dependency", "component", dependency.ComponentName)
+		t.Skip("vendor only dependency")
+	}
+
+	if !dependency.IsComponent() {
+		log.WithPrefix(t.Name()).Info("skipping function or workflow dependency, there is nothing to destroy", "WorkflowName", dependency.WorkflowName)
+		t.Skip("nothing to destroy")
+	}
+
+	log.WithPrefix(t.Name()).Info("destroying dependency", "component", dependency.ComponentName, "stack", dependency.StackName)
+	_, err := atmos.DestroyE(t, atmosOptions)
+	return err
+}
@@ -0,0 +1,92 @@
+package lifecycle
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/cloudposse/test-helpers/pkg/atmos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkDependenciesRunsDependenciesInOrder(t *testing.T) {
+	e := &Engine{}
+	dependencies := []*Dependency{
+		{ComponentName: "eks", StackName: "test", DependsOn: []string{"vpc"}},
+		{ComponentName: "vpc", StackName: "test", DependsOn: []string{"bootstrap"}},
+		{WorkflowName: "bootstrap", WorkflowFile: "bootstrap.yaml"},
+	}
+
+	var mu sync.Mutex
+	var deployed, destroyed []string
+	err := e.WalkDependencies(t, "deploy dependencies", dependencies, 2, false, func(t *testing.T, i int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		deployed = append(deployed, t.Name())
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = e.WalkDependencies(t, "destroy dependencies", dependencies, 2, true, func(t *testing.T, i int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		destroyed = append(destroyed, dependencies[i].TestName(i))
+		return nil
+	})
+	require.NoError(t, err)
+
+	prefix := t.Name() + "/"
+	assert.Equal(t, []string{prefix + "workflow/bootstrap", prefix + "test/vpc", prefix + "test/eks"}, deployed)
+	assert.Equal(t, []string{"test/eks", "test/vpc", "workflow/bootstrap"}, destroyed)
+}
+
+func TestDeployDependencyRunsFunctionAndWorkflow(t *testing.T) {
+	e := &Engine{}
+	executor := atmos.NewFakeExecutor(atmos.FakeResponse{Args: []string{"workflow"}, Stdout: "workflow complete"})
+	options := &atmos.Options{Executor: executor}
+
+	called := false
+	function := &Dependency{Function: func() error {
+		called = true
+		return nil
+	}}
+	workflow := &Dependency{WorkflowName: "bootstrap", WorkflowFile: "bootstrap.yaml"}
+
+	err := e.RunDependency(t, "deploy dependencies", function.TestName(0), func(t *testing.T) error {
+		return e.DeployDependency(t, function, options)
+	})
+	require.NoError(t, err)
+	assert.True(t, called)
+
+	err = e.RunDependency(t, "deploy dependencies", workflow.TestName(1), func(t *testing.T) error {
+		return e.DeployDependency(t, workflow, options)
+	})
+	require.NoError(t, err)
+
+	call, ok := executor.LastCall()
+	require.True(t, ok)
+	assert.Equal(t, []string{"workflow", "bootstrap", "-f", "bootstrap.yaml"}, call.Args)
+}
+
+func TestDestroyDependencySkipsDependenciesWithNothingToDestroy(t *testing.T) {
+	e := &Engine{}
+	executor := atmos.NewFakeExecutor()
+	options := &atmos.Options{Executor: executor}
+
+	dependencies := []*Dependency{
+		{WorkflowName: "bootstrap", WorkflowFile: "bootstrap.yaml"},
+		{Function: func() error { return nil }},
+		{ComponentName: "vpc", VendorOnly: true},
+	}
+	for i, dependency := range dependencies {
+		var dependencyT *testing.T
+		err := e.RunDependency(t, "destroy dependencies", dependency.TestName(i), func(t *testing.T) error {
+			dependencyT = t
+			return e.DestroyDependency(t, dependency, options)
+		})
+		require.NoError(t, err)
+		assert.True(t, dependencyT.Skipped())
+	}
+
+	assert.Empty(t, executor.Calls())
+}
@@ -0,0 +1,107 @@
+package lifecycle
+
+import (
+	"fmt"
+
+	"github.com/cloudposse/test-helpers/pkg/atmos"
+	"github.com/cloudposse/test-helpers/pkg/dag"
+)
+
+// Dependency is something the suite deploys before its tests run and destroys after they ran: an atmos component, an
+// atmos workflow or a Go function.
+type Dependency struct {
+	AdditionalVars     *map[string]interface{}
+	ComponentName      string
+	StackName          string
+	Function           func() error
+	Args               []string
+	Vendor             bool
+	VendorOnly         bool
+	Targets            []string
+	AddRandomAttribute bool
+	Options            *atmos.Options
+	WorkflowName       string
+	WorkflowFile       string
+	DependsOn          []string // Component or workflow names of the other dependencies that must be deployed before this one
+
+	// DependsOnComponents are the components in a given stack that must be deployed before this one, e.g. as resolved
+	// from settings.depends_on. Unlike DependsOn, they only refer to the component in that stack.
+	DependsOnComponents []ComponentRef
+}
+
+// ComponentRef refers to an atmos component in a stack.
+type ComponentRef struct {
+	ComponentName string
+	StackName     string
+}
+
+// Name returns the name other dependencies use to refer to this one in DependsOn: the component name, or the workflow
+// name for workflow dependencies. Function dependencies have no name and cannot be depended on.
+func (d *Dependency) Name() string {
+	if d.ComponentName != "" {
+		return d.ComponentName
+	}
+	return d.WorkflowName
+}
+
+// TestName returns the name of the subtest that deploys or destroys the dependency at index i of the suite: its stack
+// and component, its workflow, or its index for function dependencies, which have no name.
+func (d *Dependency) TestName(i int) string {
+	switch {
+	case d.Function != nil:
+		return fmt.Sprintf("function %d", i)
+	case d.WorkflowName != "" || d.WorkflowFile != "":
+		return "workflow/" + d.WorkflowName
+	case d.StackName == "":
+		return d.ComponentName
+	}
+	return d.StackName + "/" + d.ComponentName
+}
+
+// IsComponent returns true if the dependency deploys an atmos component, as opposed to running a workflow or a
+// function or only vendoring a component.
+func (d *Dependency) IsComponent() bool {
+	return d.Function == nil && d.WorkflowName == "" && d.WorkflowFile == "" && !d.VendorOnly
+}
+
+// Graph builds the dependency graph of the given dependencies, with one node per dependency at the same index. Each
+// entry in DependsOn refers to the Name of another dependency in the list; if several dependencies share that name,
+// the edge is added to each of them. Each entry in DependsOnComponents refers to the dependency with that component
+// and stack only. An error is returned for unknown references and for cycles.
+func Graph(dependencies []*Dependency) (*dag.Graph, error) {
+	g := dag.New(len(dependencies))
+
+	for i, d := range dependencies {
+		for _, name := range d.DependsOn {
+			found := false
+			for j, other := range dependencies {
+				if i != j && name != "" && other.Name() == name {
+					g.AddEdge(i, j)
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("dependency %q depends on %q, which is not a dependency of the test suite", d.Name(), name)
+			}
+		}
+
+		for _, ref := range d.DependsOnComponents {
+			found := false
+			for j, other := range dependencies {
+				if i != j && other.ComponentName == ref.ComponentName && other.StackName == ref.StackName {
+					g.AddEdge(i, j)
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("dependency %q depends on component %q in stack %q, which is not a dependency of the test suite", d.Name(), ref.ComponentName, ref.StackName)
+			}
+		}
+	}
+
+	if _, err := g.TopologicalOrder(); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
@@ -0,0 +1,87 @@
+package lifecycle
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cloudposse/test-helpers/pkg/dag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphOrdersDependenciesByDependsOn(t *testing.T) {
+	dependencies := []*Dependency{
+		{ComponentName: "eks", StackName: "test", DependsOn: []string{"vpc"}},
+		{ComponentName: "dns", StackName: "test"},
+		{ComponentName: "vpc", StackName: "test"},
+	}
+
+	g, err := Graph(dependencies)
+	require.NoError(t, err)
+
+	order, err := g.TopologicalOrder()
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 0}, order)
+}
+
+func TestGraphResolvesWorkflowDependenciesByName(t *testing.T) {
+	dependencies := []*Dependency{
+		{ComponentName: "vpc", StackName: "test", DependsOn: []string{"bootstrap"}},
+		{WorkflowName: "bootstrap", WorkflowFile: "bootstrap.yaml"},
+	}
+
+	g, err := Graph(dependencies)
+	require.NoError(t, err)
+
+	order, err := g.TopologicalOrder()
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 0}, order)
+}
+
+func TestGraphKeysComponentDependenciesByStack(t *testing.T) {
+	// The same vpc is deployed in two stacks, and the vpc in each stack depends on the bootstrap of the other one.
+	dependencies := []*Dependency{
+		{ComponentName: "vpc", StackName: "use1", DependsOnComponents: []ComponentRef{{ComponentName: "bootstrap", StackName: "use1"}}},
+		{ComponentName: "bootstrap", StackName: "use1", DependsOnComponents: []ComponentRef{{ComponentName: "vpc", StackName: "use2"}}},
+		{ComponentName: "vpc", StackName: "use2"},
+	}
+
+	g, err := Graph(dependencies)
+	require.NoError(t, err, "the vpc in use1 does not depend on the vpc in use2")
+
+	order, err := g.TopologicalOrder()
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 1, 0}, order)
+
+	_, err = Graph([]*Dependency{{ComponentName: "eks", StackName: "use1", DependsOnComponents: []ComponentRef{{ComponentName: "vpc", StackName: "use2"}}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `depends on component "vpc" in stack "use2"`)
+}
+
+func TestGraphFailsOnUnknownDependency(t *testing.T) {
+	dependencies := []*Dependency{
+		{ComponentName: "eks", StackName: "test", DependsOn: []string{"vpc"}},
+	}
+
+	_, err := Graph(dependencies)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `depends on "vpc"`)
+}
+
+func TestGraphFailsOnCycles(t *testing.T) {
+	dependencies := []*Dependency{
+		{ComponentName: "eks", StackName: "test", DependsOn: []string{"vpc"}},
+		{ComponentName: "vpc", StackName: "test", DependsOn: []string{"eks"}},
+	}
+
+	_, err := Graph(dependencies)
+	var cycleErr dag.CycleError
+	require.True(t, errors.As(err, &cycleErr))
+}
+
+func TestDependencyTestName(t *testing.T) {
+	assert.Equal(t, "test/vpc", (&Dependency{ComponentName: "vpc", StackName: "test"}).TestName(0))
+	assert.Equal(t, "vpc", (&Dependency{ComponentName: "vpc", VendorOnly: true}).TestName(1))
+	assert.Equal(t, "workflow/bootstrap", (&Dependency{WorkflowName: "bootstrap", WorkflowFile: "bootstrap.yaml"}).TestName(2))
+	assert.Equal(t, "function 3", (&Dependency{Function: func() error { return nil }}).TestName(3))
+}
@@ -0,0 +1,79 @@
+package lifecycle
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/cloudposse/test-helpers/pkg/atmos"
+	"github.com/cloudposse/test-helpers/pkg/atmos/diagnostics"
+)
+
+// EnableDiagnostics makes the engine collect a diagnostics bundle into config.DiagnosticsDir whenever a phase fails, and
+// makes the atmos options built from the config record every command in it. Nothing is collected if DiagnosticsDir is
+// empty.
+func (e *Engine) EnableDiagnostics(config *Config) {
+	if config.DiagnosticsDir == "" || e.Diagnostics != nil {
+		return
+	}
+
+	e.Diagnostics = diagnostics.New()
+	config.Recorder = atmos.Recorders{config.Recorder, e.Diagnostics}
+
+	// A setup phase that fails stops the suite without running the teardown, so the bundle has to be collected in the
+	// failed phase itself.
+	e.Hooks.OnPhaseFailure(AnyPhase, FuncHook(func(t *testing.T) error {
+		e.CollectDiagnostics(t, config)
+
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.phaseFailureCollected = true
+		return nil
+	}))
+}
+
+// AddDiagnosticsTarget registers a deployed component, whose rendered stack config and terraform state are collected
+// into the diagnostics bundle. It does nothing unless diagnostics are enabled.
+func (e *Engine) AddDiagnosticsTarget(options *atmos.Options) {
+	if e.Diagnostics != nil {
+		e.Diagnostics.AddTarget(options)
+	}
+}
+
+// CollectDiagnostics writes a diagnostics bundle named after t to config.DiagnosticsDir, e.g. when a test failed before
+// destroying its component. It does nothing unless diagnostics are enabled. A bundle that cannot be written is logged,
+// but does not fail the test, since the test has failed already.
+func (e *Engine) CollectDiagnostics(t *testing.T, config *Config) {
+	if e.Diagnostics == nil {
+		return
+	}
+
+	path, err := e.Diagnostics.Collect(t, config.DiagnosticsDir, t.Name(), config.TempDir)
+	if err != nil {
+		log.WithPrefix(t.Name()).Warn("failed to write diagnostics bundle", "error", err)
+		return
+	}
+	log.WithPrefix(t.Name()).Info("wrote diagnostics bundle", "path", path)
+}
+
+// CollectFailedSuiteDiagnostics collects a diagnostics bundle if a test of the suite failed. It is the first teardown
+// phase, so that the bundle is collected before the dependencies and the temp dir are destroyed. It is skipped if a
+// failed phase already collected the bundle of the failure.
+func (e *Engine) CollectFailedSuiteDiagnostics(t *testing.T, suiteT *testing.T, config *Config) {
+	const phaseName = "teardown/collect diagnostics"
+	if e.Diagnostics == nil || e.collectedOnPhaseFailure() || !suiteT.Failed() {
+		e.LogPhaseStatus(t, phaseName, "skipped")
+		return
+	}
+
+	e.LogPhaseStatus(t, phaseName, "started")
+	e.CollectDiagnostics(t, config)
+	e.LogPhaseStatus(t, phaseName, "completed")
+}
+
+// collectedOnPhaseFailure returns true if a failed phase already collected a diagnostics bundle.
+func (e *Engine) collectedOnPhaseFailure() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.phaseFailureCollected
+}
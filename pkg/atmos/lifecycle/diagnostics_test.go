@@ -0,0 +1,76 @@
+package lifecycle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudposse/test-helpers/pkg/atmos"
+	"github.com/cloudposse/test-helpers/pkg/atmos/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnableDiagnosticsRecordsCommandsAndCollectsOnFailure(t *testing.T) {
+	e := &Engine{}
+	r := report.New(t.Name())
+	config := &Config{DiagnosticsDir: t.TempDir(), TempDir: t.TempDir(), Recorder: r}
+
+	e.EnableDiagnostics(config)
+	require.NotNil(t, e.Diagnostics)
+	assert.Len(t, e.Hooks.matching("deploy dependencies", hookOnFailure), 1)
+
+	config.Recorder.RecordCommand(atmos.CommandRecord{Binary: "atmos", Args: []string{"version"}})
+	assert.Len(t, r.Commands(), 1)
+	assert.Len(t, e.Diagnostics.Commands(), 1)
+
+	e.CollectDiagnostics(t, config)
+	_, err := os.Stat(filepath.Join(config.DiagnosticsDir, t.Name()+".diagnostics.tar.gz"))
+	assert.NoError(t, err)
+}
+
+func TestEnableDiagnosticsDoesNothingWithoutDiagnosticsDir(t *testing.T) {
+	e := &Engine{}
+	config := &Config{}
+
+	e.EnableDiagnostics(config)
+	assert.Nil(t, e.Diagnostics)
+	assert.Nil(t, config.Recorder)
+	assert.Empty(t, e.Hooks.matching("deploy dependencies", hookOnFailure))
+
+	e.AddDiagnosticsTarget(&atmos.Options{Component: "vpc", Stack: "test"})
+	e.CollectDiagnostics(t, config)
+}
+
+func TestCollectFailedSuiteDiagnosticsSkippedWhenSuitePassed(t *testing.T) {
+	e := &Engine{}
+	config := &Config{DiagnosticsDir: t.TempDir()}
+	e.EnableDiagnostics(config)
+
+	suiteT := t
+	e.RunPhase(t, "teardown/collect diagnostics", func(t *testing.T) { e.CollectFailedSuiteDiagnostics(t, suiteT, config) })
+	assert.True(t, e.PhaseSkipped("teardown/collect diagnostics"))
+
+	entries, err := os.ReadDir(config.DiagnosticsDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestCollectFailedSuiteDiagnosticsSkippedAfterPhaseFailureBundle(t *testing.T) {
+	e := &Engine{}
+	config := &Config{DiagnosticsDir: t.TempDir(), TempDir: t.TempDir()}
+	e.EnableDiagnostics(config)
+
+	hooks := e.Hooks.matching("deploy dependencies", hookOnFailure)
+	require.Len(t, hooks, 1)
+	require.NoError(t, hooks[0].run(t, "deploy dependencies"))
+	assert.True(t, e.collectedOnPhaseFailure())
+
+	suiteT := t
+	e.RunPhase(t, "teardown/collect diagnostics", func(t *testing.T) { e.CollectFailedSuiteDiagnostics(t, suiteT, config) })
+	assert.True(t, e.PhaseSkipped("teardown/collect diagnostics"))
+
+	entries, err := os.ReadDir(config.DiagnosticsDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "only the bundle of the failed phase is written")
+}
@@ -0,0 +1,228 @@
+// Package lifecycle is the suite core shared by the component and examples test helpers. It runs the setup and teardown
+// phases of a suite, deploys and destroys its dependencies, records them in the run report and provides the phases
+// that both helpers compose their lifecycle from.
+package lifecycle
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/cloudposse/test-helpers/pkg/atmos/diagnostics"
+	"github.com/cloudposse/test-helpers/pkg/atmos/report"
+	"github.com/stretchr/testify/assert"
+)
+
+var statusStyles = map[string]lipgloss.Style{
+	"skipped":   lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Italic(true),
+	"completed": lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true),
+	"started":   lipgloss.NewStyle().Foreground(lipgloss.Color("14")),
+	"failed":    lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true).Underline(true),
+}
+
+// Fallback style for unknown statuses
+var fallbackStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("7"))
+
+// Engine runs the phases of a test suite. The zero value is ready to use.
+type Engine struct {
+	// Report records every phase and dependency that ran, if set.
+	Report *report.Reporter
+
+	// OnPhaseStatus is called with every status logged for a phase, e.g. to record it in a state ledger.
+	OnPhaseStatus func(t *testing.T, phaseName string, status string)
+
+	// Hooks run before and after the phases, and when they fail.
+	Hooks Hooks
+
+	// Diagnostics collects a diagnostics bundle when a phase fails. It is set by EnableDiagnostics.
+	Diagnostics *diagnostics.Collector
+
+	mu            sync.Mutex
+	skippedPhases map[string]bool
+
+	// phaseFailureCollected is set once a failed phase collected a diagnostics bundle.
+	phaseFailureCollected bool
+}
+
+// LogPhaseStatus logs the status of a phase with appropriate styling. A phase logged as skipped is reported as skipped
+// by RunPhase.
+func (e *Engine) LogPhaseStatus(t *testing.T, phaseName string, status string) {
+	// Get the style for the given status, fallback if not found
+	style, ok := statusStyles[status]
+	if !ok {
+		style = fallbackStyle
+	}
+
+	// Create the styled output
+	output := lipgloss.NewStyle().Bold(true).Underline(true).Render(phaseName) + " → " + style.Render(status)
+
+	if e.OnPhaseStatus != nil {
+		e.OnPhaseStatus(t, phaseName, status)
+	}
+
+	if status == "skipped" {
+		e.markPhaseSkipped(phaseName)
+		log.WithPrefix(t.Name()).Warn(output)
+	} else if status == "failed" {
+		log.WithPrefix(t.Name()).Error(output)
+	} else {
+		log.WithPrefix(t.Name()).Info(output)
+	}
+}
+
+// RunPhase runs a setup or teardown phase as a subtest named after the phase, so that go test -json and tools such as
+// gotestsum report every phase on its own, with its duration and whether it passed, was skipped or failed. A phase that
+// logs itself as skipped is reported as skipped. The hooks registered for the phase run in its subtest. The phase is
+// recorded in the run report. It returns false if the phase failed.
+func (e *Engine) RunPhase(t *testing.T, phaseName string, fn func(t *testing.T)) bool {
+	var phaseT *testing.T
+	startedAt := time.Now()
+	ok := t.Run(phaseName, func(t *testing.T) {
+		phaseT = t
+		defer e.runAfterHooks(t, phaseName)
+
+		if err := e.Hooks.run(t, phaseName, hookBefore, false); err != nil {
+			t.Fatal(err)
+		}
+
+		fn(t)
+
+		if e.PhaseSkipped(phaseName) {
+			t.Skip("phase skipped")
+		}
+	})
+	e.recordPhase(phaseName, phaseT, startedAt, "")
+	return ok
+}
+
+// runAfterHooks runs the failure hooks of the phase if it failed, or its after hooks if it completed. It is deferred, so
+// that the failure hooks also run when the phase stopped its subtest with t.FailNow.
+func (e *Engine) runAfterHooks(t *testing.T, phaseName string) {
+	switch {
+	case t.Failed():
+		if err := e.Hooks.run(t, phaseName, hookOnFailure, true); err != nil {
+			t.Error(err)
+		}
+	case t.Skipped() || e.PhaseSkipped(phaseName):
+	default:
+		if err := e.Hooks.run(t, phaseName, hookAfter, false); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// RunSetupPhase runs a setup phase with RunPhase and stops the setup if it fails, since every later phase depends on
+// the ones before it.
+func (e *Engine) RunSetupPhase(t *testing.T, phaseName string, fn func(t *testing.T)) {
+	if !e.RunPhase(t, phaseName, fn) {
+		t.FailNow()
+	}
+}
+
+// RunSetup runs the setup phases in order, stopping at the first one that fails.
+func (e *Engine) RunSetup(t *testing.T, phases Pipeline) {
+	for _, phase := range phases {
+		e.RunSetupPhase(t, phase.Name, phase.Run)
+	}
+}
+
+// RunTeardown runs the teardown phases in order. They all run even when an earlier one failed, so that as much as
+// possible is cleaned up. A failed phase still fails the suite. It returns false if any phase failed.
+func (e *Engine) RunTeardown(t *testing.T, phases Pipeline) bool {
+	ok := true
+	for _, phase := range phases {
+		if !e.RunPhase(t, phase.Name, phase.Run) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// RunDependency runs the deploy or destroy of a single dependency as a subtest of the phase, named name, and records
+// it in the run report as part of the phase. fn may skip the subtest. An error returned by fn fails the subtest and is
+// returned, so that the dependency graph walk stops scheduling further dependencies. A subtest that fails without fn
+// returning an error, e.g. through t.FailNow, returns an error as well.
+func (e *Engine) RunDependency(t *testing.T, phaseName string, name string, fn func(t *testing.T) error) error {
+	var dependencyT *testing.T
+	var err error
+	startedAt := time.Now()
+	ok := t.Run(name, func(t *testing.T) {
+		dependencyT = t
+		err = fn(t)
+		if err != nil {
+			t.Error(err)
+		}
+	})
+	if !ok && err == nil {
+		err = fmt.Errorf("dependency %s failed", name)
+	}
+
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+	e.recordPhase(phaseName+"/"+name, dependencyT, startedAt, message)
+	return err
+}
+
+// PhaseSkipped returns true if the phase was logged as skipped.
+func (e *Engine) PhaseSkipped(phaseName string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.skippedPhases[phaseName]
+}
+
+func (e *Engine) markPhaseSkipped(phaseName string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.skippedPhases == nil {
+		e.skippedPhases = map[string]bool{}
+	}
+	e.skippedPhases[phaseName] = true
+}
+
+// InitReport records the phases of the suite in the run report r and makes the atmos options built from config record
+// every atmos command in it. If r is nil, a report named after t is created. The report is returned.
+func (e *Engine) InitReport(t *testing.T, config *Config, r *report.Reporter) *report.Reporter {
+	if r == nil {
+		r = report.New(t.Name())
+	}
+
+	config.Recorder = r
+	e.Report = r
+	return r
+}
+
+// WriteReport writes the JUnit XML and JSON run reports to dir. Nothing is written if dir is empty.
+func (e *Engine) WriteReport(t *testing.T, dir string) {
+	if e.Report == nil || dir == "" {
+		return
+	}
+
+	junitPath, jsonPath, err := e.Report.Write(dir)
+	if !assert.NoError(t, err, "writing the run report") {
+		return
+	}
+	log.WithPrefix(t.Name()).Info("wrote run report", "junit", junitPath, "json", jsonPath)
+}
+
+// recordPhase records a phase that ran as the subtest phaseT in the run report.
+func (e *Engine) recordPhase(phaseName string, phaseT *testing.T, startedAt time.Time, message string) {
+	if e.Report == nil || phaseT == nil {
+		return
+	}
+
+	status := report.StatusPassed
+	switch {
+	case phaseT.Failed():
+		status = report.StatusFailed
+	case phaseT.Skipped():
+		status = report.StatusSkipped
+	}
+	e.Report.RecordPhase(phaseName, status, startedAt, time.Since(startedAt), message)
+}
@@ -0,0 +1,126 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/cloudposse/test-helpers/pkg/atmos/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPhaseReportsPhaseAsSubtest(t *testing.T) {
+	e := &Engine{}
+
+	var phaseT *testing.T
+	ok := e.RunPhase(t, "setup/bootstrap temp dir", func(t *testing.T) {
+		phaseT = t
+		e.LogPhaseStatus(t, "setup/bootstrap temp dir", "completed")
+	})
+	require.True(t, ok)
+	assert.Equal(t, t.Name()+"/setup/bootstrap_temp_dir", phaseT.Name())
+	assert.False(t, phaseT.Skipped())
+}
+
+func TestRunPhaseReportsSkippedPhase(t *testing.T) {
+	e := &Engine{}
+
+	var phaseT *testing.T
+	ok := e.RunPhase(t, "setup/validate stacks", func(t *testing.T) {
+		phaseT = t
+		e.LogPhaseStatus(t, "setup/validate stacks", "skipped")
+	})
+	require.True(t, ok)
+	assert.True(t, phaseT.Skipped())
+	assert.True(t, e.PhaseSkipped("setup/validate stacks"))
+}
+
+func TestRunSetupAndTeardownRunPhasesInOrder(t *testing.T) {
+	var statuses []string
+	e := &Engine{OnPhaseStatus: func(t *testing.T, phaseName string, status string) {
+		statuses = append(statuses, phaseName+" → "+status)
+	}}
+
+	var ran []string
+	phase := func(name string) Phase {
+		return Phase{Name: name, Run: func(t *testing.T) {
+			ran = append(ran, name)
+			e.LogPhaseStatus(t, name, "completed")
+		}}
+	}
+
+	e.RunSetup(t, Pipeline{phase("bootstrap"), phase("deploy dependencies")})
+	ok := e.RunTeardown(t, Pipeline{phase("destroy dependencies"), phase("destroy temp dir")})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"bootstrap", "deploy dependencies", "destroy dependencies", "destroy temp dir"}, ran)
+	assert.Equal(t, []string{
+		"bootstrap → completed",
+		"deploy dependencies → completed",
+		"destroy dependencies → completed",
+		"destroy temp dir → completed",
+	}, statuses)
+}
+
+func TestRunDependency(t *testing.T) {
+	e := &Engine{}
+
+	var dependencyT *testing.T
+	err := e.RunDependency(t, "deploy dependencies", "default-test/vpc", func(t *testing.T) error {
+		dependencyT = t
+		t.Skip("already deployed by a previous run")
+		return errors.New("unreachable")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, t.Name()+"/default-test/vpc", dependencyT.Name())
+	assert.True(t, dependencyT.Skipped())
+}
+
+func TestRunDependencyReturnsErrorWhenDependencyFailsNow(t *testing.T) {
+	// A failed subtest fails its parent test, so the dependency fails in a test binary of its own.
+	if os.Getenv("LIFECYCLE_TEST_DEPENDENCY_FAIL_NOW") == "1" {
+		e := &Engine{}
+		err := e.RunDependency(t, "deploy dependencies", "default-test/vpc", func(t *testing.T) error {
+			t.FailNow()
+			return nil
+		})
+		fmt.Printf("RunDependency returned: %v\n", err)
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestRunDependencyReturnsErrorWhenDependencyFailsNow$", "-test.v")
+	cmd.Env = append(os.Environ(), "LIFECYCLE_TEST_DEPENDENCY_FAIL_NOW=1")
+	output, err := cmd.CombinedOutput()
+	assert.Error(t, err, "the failed dependency fails the test")
+	assert.Contains(t, string(output), "RunDependency returned: dependency default-test/vpc failed")
+}
+
+func TestRunPhaseRecordsPhasesInReport(t *testing.T) {
+	e := &Engine{Report: report.New(t.Name())}
+
+	e.RunPhase(t, "setup/validate stacks", func(t *testing.T) { e.LogPhaseStatus(t, "setup/validate stacks", "skipped") })
+	e.RunPhase(t, "deploy dependencies", func(t *testing.T) {
+		err := e.RunDependency(t, "deploy dependencies", "default-test/vpc", func(t *testing.T) error { return nil })
+		require.NoError(t, err)
+	})
+
+	phases := e.Report.Phases()
+	require.Len(t, phases, 3)
+	assert.Equal(t, "setup/validate stacks", phases[0].Name)
+	assert.Equal(t, report.StatusSkipped, phases[0].Status)
+	assert.Equal(t, "deploy dependencies/default-test/vpc", phases[1].Name)
+	assert.Equal(t, report.StatusPassed, phases[1].Status)
+	assert.Equal(t, "deploy dependencies", phases[2].Name)
+	assert.Equal(t, report.StatusPassed, phases[2].Status)
+}
+
+func TestDestroyTempDirKeepsItWhenPhasesAreSkipped(t *testing.T) {
+	e := &Engine{}
+	config := &Config{TempDir: t.TempDir(), StateDir: t.TempDir(), SkipDestroyComponent: true}
+
+	e.RunPhase(t, "teardown/destroy temp dir", func(t *testing.T) { e.DestroyTempDir(t, config) })
+	assert.True(t, e.PhaseSkipped("teardown/destroy temp dir"))
+	assert.DirExists(t, config.TempDir)
+}
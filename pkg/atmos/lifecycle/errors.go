@@ -0,0 +1,10 @@
+package lifecycle
+
+import "fmt"
+
+// PhaseNotFound is returned when a pipeline does not contain a phase with the given name
+type PhaseNotFound string
+
+func (name PhaseNotFound) Error() string {
+	return fmt.Sprintf("Pipeline doesn't contain the phase %q", string(name))
+}
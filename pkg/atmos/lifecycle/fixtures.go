@@ -0,0 +1,122 @@
+package lifecycle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	tt "github.com/cloudposse/test-helpers/pkg/testing"
+	"github.com/gruntwork-io/terratest/modules/aws"
+)
+
+// TemplateSuffix is the suffix of the fixture files that are rendered with text/template when they are copied. The
+// rendered file is written without the suffix, e.g. stacks/catalog/vpc.yaml.tmpl is written to stacks/catalog/vpc.yaml.
+const TemplateSuffix = ".tmpl"
+
+// FixtureData is the data fixture templates are rendered with.
+type FixtureData struct {
+	RandomIdentifier string                 // The random identifier of the suite, to make resource names unique
+	TempDir          string                 // The temp dir the fixtures are copied to
+	StateDir         string                 // The terraform state directory
+	Values           map[string]interface{} // The TemplateValues of the config file
+
+	t         tt.TestingT
+	accountID string
+}
+
+// NewFixtureData returns the data to render the fixture templates of the suite with.
+func NewFixtureData(t tt.TestingT, config *Config) *FixtureData {
+	return &FixtureData{
+		RandomIdentifier: config.RandomIdentifier,
+		TempDir:          config.TempDir,
+		StateDir:         config.StateDir,
+		Values:           config.TemplateValues,
+		t:                t,
+	}
+}
+
+// AWSAccountID returns the ID of the AWS account of the current credentials. It is only looked up when a template uses
+// it, so that fixtures that do not need AWS can be rendered without credentials.
+func (d *FixtureData) AWSAccountID() (string, error) {
+	if d.accountID == "" {
+		accountID, err := aws.GetAccountIdE(d.t)
+		if err != nil {
+			return "", err
+		}
+		d.accountID = accountID
+	}
+	return d.accountID, nil
+}
+
+// AWSRegion returns the AWS region set by AWS_REGION or AWS_DEFAULT_REGION.
+func (d *FixtureData) AWSRegion() (string, error) {
+	for _, name := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region := os.Getenv(name); region != "" {
+			return region, nil
+		}
+	}
+	return "", errors.New("neither AWS_REGION nor AWS_DEFAULT_REGION is set")
+}
+
+// CopyFixtures copies every file and directory in srcDir to destDir like CopyDirectoryContents, except that files
+// ending in TemplateSuffix are rendered with data and written without the suffix. A template that refers to a missing
+// value fails the copy, instead of rendering "<no value>" into a stack. Atmos templates in such a file, e.g.
+// {{ .component }}, are rendered as well and must be escaped as string literals, e.g. {{ "{{ .component }}" }}.
+func CopyFixtures(srcDir string, destDir string, data *FixtureData) error {
+	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		destPath := filepath.Join(destDir, relPath)
+
+		if info.IsDir() {
+			return os.MkdirAll(destPath, info.Mode())
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if strings.HasSuffix(path, TemplateSuffix) {
+			content, err = renderFixture(relPath, content, data)
+			if err != nil {
+				return err
+			}
+			destPath = strings.TrimSuffix(destPath, TemplateSuffix)
+		}
+
+		return os.WriteFile(destPath, content, info.Mode())
+	})
+}
+
+func renderFixture(name string, content []byte, data *FixtureData) ([]byte, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("parsing fixture template %s: %w", name, err)
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return nil, fmt.Errorf("rendering fixture template %s: %w", name, err)
+	}
+	return rendered.Bytes(), nil
+}
+
+// CopyFixturesToTempDir copies config.FixturesDir to destDir with CopyFixtures, logging the copy as skipped if either
+// of them does not exist.
+func (e *Engine) CopyFixturesToTempDir(t *testing.T, config *Config, destDir string) {
+	e.copyDirectory(t, config.FixturesDir, destDir, func() error {
+		return CopyFixtures(config.FixturesDir, destDir, NewFixtureData(t, config))
+	})
+}
@@ -0,0 +1,77 @@
+package lifecycle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyFixturesRendersTemplates(t *testing.T) {
+	srcDir, destDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(srcDir, "stacks", "catalog"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "stacks", "catalog", "vpc.yaml.tmpl"), []byte(
+		"name: vpc-{{ .RandomIdentifier }}\nregion: {{ .AWSRegion }}\ncidr: {{ .Values.cidr_block }}\nstate: {{ .StateDir }}\n",
+	), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "vendor.yaml"), []byte("version: \"{{ .Version }}\"\n"), 0644))
+	t.Setenv("AWS_REGION", "us-east-2")
+
+	data := NewFixtureData(t, &Config{
+		RandomIdentifier: "abc123",
+		StateDir:         "/tmp/state",
+		TemplateValues:   map[string]interface{}{"cidr_block": "10.0.0.0/16"},
+	})
+	err := CopyFixtures(srcDir, destDir, data)
+	require.NoError(t, err)
+
+	rendered, err := os.ReadFile(filepath.Join(destDir, "stacks", "catalog", "vpc.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "name: vpc-abc123\nregion: us-east-2\ncidr: 10.0.0.0/16\nstate: /tmp/state\n", string(rendered))
+	assert.NoFileExists(t, filepath.Join(destDir, "stacks", "catalog", "vpc.yaml.tmpl"))
+
+	copied, err := os.ReadFile(filepath.Join(destDir, "vendor.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "version: \"{{ .Version }}\"\n", string(copied), "files without the template suffix are copied verbatim")
+}
+
+func TestCopyFixturesKeepsEscapedAtmosTemplates(t *testing.T) {
+	srcDir, destDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "main.yaml.tmpl"), []byte(
+		"path: {{ .StateDir }}/{{ \"{{ .component }}\" }}/terraform.tfstate\n",
+	), 0644))
+
+	err := CopyFixtures(srcDir, destDir, NewFixtureData(t, &Config{StateDir: "/tmp/state"}))
+	require.NoError(t, err)
+
+	rendered, err := os.ReadFile(filepath.Join(destDir, "main.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "path: /tmp/state/{{ .component }}/terraform.tfstate\n", string(rendered))
+
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "main.yaml.tmpl"), []byte("path: {{ .component }}\n"), 0644))
+	assert.Error(t, CopyFixtures(srcDir, destDir, NewFixtureData(t, &Config{})), "unescaped atmos templates fail the copy")
+}
+
+func TestCopyFixturesFailsOnMissingValue(t *testing.T) {
+	srcDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "vpc.yaml.tmpl"), []byte("cidr: {{ .Values.cidr_block }}\n"), 0644))
+
+	err := CopyFixtures(srcDir, t.TempDir(), NewFixtureData(t, &Config{}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rendering fixture template vpc.yaml.tmpl")
+}
+
+func TestFixtureDataAWSRegionRequiresEnv(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "eu-west-1")
+
+	data := NewFixtureData(t, &Config{})
+	region, err := data.AWSRegion()
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", region)
+
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	_, err = data.AWSRegion()
+	assert.Error(t, err)
+}
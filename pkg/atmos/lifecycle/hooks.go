@@ -0,0 +1,135 @@
+package lifecycle
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/gruntwork-io/terratest/modules/shell"
+)
+
+// AnyPhase is the phase name hooks are registered under to run around every phase.
+const AnyPhase = "*"
+
+type hookKind int
+
+const (
+	hookBefore hookKind = iota
+	hookAfter
+	hookOnFailure
+)
+
+// Hook is a Go function or a shell command that runs around a phase.
+type Hook struct {
+	Func    func(t *testing.T) error
+	Command *shell.Command
+}
+
+// FuncHook returns a hook that runs fn.
+func FuncHook(fn func(t *testing.T) error) Hook {
+	return Hook{Func: fn}
+}
+
+// CommandHook returns a hook that runs the command with the given args. TEST_PHASE is set to the name of the phase it
+// runs around.
+func CommandHook(command string, args ...string) Hook {
+	return Hook{Command: &shell.Command{Command: command, Args: args}}
+}
+
+// String returns the function or command line of the hook, for logging.
+func (h Hook) String() string {
+	if h.Command != nil {
+		return strings.Join(append([]string{h.Command.Command}, h.Command.Args...), " ")
+	}
+	return "function"
+}
+
+func (h Hook) run(t *testing.T, phaseName string) error {
+	if h.Func != nil {
+		return h.Func(t)
+	}
+	if h.Command == nil {
+		return nil
+	}
+
+	command := *h.Command
+	command.Env = map[string]string{"TEST_PHASE": phaseName}
+	for k, v := range h.Command.Env {
+		command.Env[k] = v
+	}
+	_, err := shell.RunCommandAndGetOutputE(t, command)
+	return err
+}
+
+type registeredHook struct {
+	phaseName string
+	kind      hookKind
+	hook      Hook
+}
+
+// Hooks is a registry of the hooks that run before and after phases, and when they fail, keyed by phase name. Hooks
+// registered for the same phase run in the order they were registered. The zero value is ready to use.
+type Hooks struct {
+	mu    sync.Mutex
+	hooks []registeredHook
+}
+
+// BeforePhase registers hooks that run in the phase's subtest before the phase. If a hook fails, the phase fails
+// without running.
+func (h *Hooks) BeforePhase(phaseName string, hooks ...Hook) {
+	h.register(phaseName, hookBefore, hooks)
+}
+
+// AfterPhase registers hooks that run in the phase's subtest after the phase completed. They don't run if the phase
+// failed or was skipped. If a hook fails, the phase fails.
+func (h *Hooks) AfterPhase(phaseName string, hooks ...Hook) {
+	h.register(phaseName, hookAfter, hooks)
+}
+
+// OnPhaseFailure registers hooks that run in the phase's subtest after the phase failed, e.g. to collect diagnostics.
+// They all run, even if one of them fails.
+func (h *Hooks) OnPhaseFailure(phaseName string, hooks ...Hook) {
+	h.register(phaseName, hookOnFailure, hooks)
+}
+
+func (h *Hooks) register(phaseName string, kind hookKind, hooks []Hook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, hook := range hooks {
+		h.hooks = append(h.hooks, registeredHook{phaseName: phaseName, kind: kind, hook: hook})
+	}
+}
+
+// matching returns the hooks of the given kind registered for the phase or for AnyPhase.
+func (h *Hooks) matching(phaseName string, kind hookKind) []Hook {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var hooks []Hook
+	for _, registered := range h.hooks {
+		if registered.kind == kind && (registered.phaseName == phaseName || registered.phaseName == AnyPhase) {
+			hooks = append(hooks, registered.hook)
+		}
+	}
+	return hooks
+}
+
+// run runs the hooks of the given kind for the phase. It stops at the first hook that fails, unless all is set, and
+// returns the errors of the failed hooks.
+func (h *Hooks) run(t *testing.T, phaseName string, kind hookKind, all bool) error {
+	var errs []error
+	for _, hook := range h.matching(phaseName, kind) {
+		log.WithPrefix(t.Name()).Info("running hook", "phase", phaseName, "hook", hook.String())
+		if err := hook.run(t, phaseName); err != nil {
+			log.WithPrefix(t.Name()).Error("hook failed", "phase", phaseName, "hook", hook.String(), "error", err)
+			errs = append(errs, err)
+			if !all {
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,84 @@
+package lifecycle
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPhaseRunsHooksAroundPhase(t *testing.T) {
+	e := &Engine{}
+
+	var ran []string
+	hook := func(name string) Hook {
+		return FuncHook(func(t *testing.T) error {
+			ran = append(ran, name)
+			return nil
+		})
+	}
+	e.Hooks.BeforePhase("deploy dependencies", hook("before"))
+	e.Hooks.BeforePhase(AnyPhase, hook("before any"))
+	e.Hooks.AfterPhase("deploy dependencies", hook("after"))
+	e.Hooks.AfterPhase("destroy dependencies", hook("after destroy"))
+	e.Hooks.OnPhaseFailure("deploy dependencies", hook("on failure"))
+
+	ok := e.RunPhase(t, "deploy dependencies", func(t *testing.T) { ran = append(ran, "phase") })
+	require.True(t, ok)
+	assert.Equal(t, []string{"before", "before any", "phase", "after"}, ran)
+}
+
+func TestRunPhaseSkipsAfterHooksOfSkippedPhase(t *testing.T) {
+	e := &Engine{}
+
+	ran := false
+	e.Hooks.AfterPhase("setup/validate stacks", FuncHook(func(t *testing.T) error {
+		ran = true
+		return nil
+	}))
+
+	e.RunPhase(t, "setup/validate stacks", func(t *testing.T) { e.LogPhaseStatus(t, "setup/validate stacks", "skipped") })
+	assert.False(t, ran)
+}
+
+func TestCommandHookSetsPhaseName(t *testing.T) {
+	e := &Engine{}
+	path := filepath.Join(t.TempDir(), "phase")
+
+	e.Hooks.AfterPhase("deploy dependencies", CommandHook("sh", "-c", `printf "$TEST_PHASE" > "$0"`, path))
+
+	ok := e.RunPhase(t, "deploy dependencies", func(t *testing.T) {})
+	require.True(t, ok)
+
+	phase, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "deploy dependencies", string(phase))
+}
+
+func TestHooksRunStopsAtFirstFailureUnlessAll(t *testing.T) {
+	h := &Hooks{}
+
+	ran := 0
+	failing := FuncHook(func(t *testing.T) error {
+		ran++
+		return errors.New("failed")
+	})
+	h.OnPhaseFailure("deploy dependencies", failing, failing)
+	h.AfterPhase("deploy dependencies", failing, failing)
+
+	err := h.run(t, "deploy dependencies", hookOnFailure, true)
+	assert.Error(t, err)
+	assert.Equal(t, 2, ran)
+
+	err = h.run(t, "deploy dependencies", hookAfter, false)
+	assert.Error(t, err)
+	assert.Equal(t, 3, ran)
+}
+
+func TestHookString(t *testing.T) {
+	assert.Equal(t, "./seed.sh --stack test", CommandHook("./seed.sh", "--stack", "test").String())
+	assert.Equal(t, "function", FuncHook(func(t *testing.T) error { return nil }).String())
+}
@@ -0,0 +1,98 @@
+package lifecycle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	log "github.com/charmbracelet/log"
+	"github.com/cloudposse/test-helpers/pkg/atmos"
+	"github.com/cloudposse/test-helpers/pkg/atmos/plugincache"
+	"github.com/stretchr/testify/require"
+)
+
+// LocalBackendOverrideFile is the terraform override file that replaces the backend of a component with a local one.
+// Terraform merges override files into the configuration last, so it wins over the backend.tf.json generated by atmos.
+const LocalBackendOverrideFile = "backend_override.tf.json"
+
+const localBackendOverride = `{
+  "terraform": {
+    "backend": {
+      "local": {}
+    }
+  }
+}
+`
+
+// GenerateLocalBackends writes a local backend override into every component under componentsDir when
+// Config.LocalBackend is set. The override leaves the path of the state out, since several atmos components share a
+// terraform component; SetLocalBackendOptions passes it to terraform init for each of them instead.
+func (e *Engine) GenerateLocalBackends(t *testing.T, config *Config, componentsDir string) {
+	const phaseName = "setup/generate local backends"
+	if config.SkipSetupTestSuite || !config.LocalBackend {
+		e.LogPhaseStatus(t, phaseName, "skipped")
+		return
+	}
+
+	e.LogPhaseStatus(t, phaseName, "started")
+
+	err := WriteLocalBackendOverrides(componentsDir)
+	require.NoError(t, err)
+
+	e.LogPhaseStatus(t, phaseName, "completed")
+}
+
+// WriteLocalBackendOverrides writes the local backend override into every terraform component under componentsDir,
+// e.g. again after a component was replaced.
+func WriteLocalBackendOverrides(componentsDir string) error {
+	componentDirs, err := plugincache.ComponentDirs(componentsDir)
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range componentDirs {
+		path := filepath.Join(dir, LocalBackendOverrideFile)
+		log.Debug("writing local backend override", "path", path)
+		if err := os.WriteFile(path, []byte(localBackendOverride), 0644); err != nil {
+			return fmt.Errorf("writing local backend override %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// LocalBackendDir returns the directory that the state of the component in the stack is kept in, so that the state of
+// every component in every stack, and of every suite with its own StateDir, is kept apart.
+func LocalBackendDir(config *Config, componentName string, stackName string) string {
+	return filepath.Join(config.StateDir, stackName, componentName)
+}
+
+// SetLocalBackendOptions points the local backend of the component at its directory under Config.StateDir when
+// Config.LocalBackend is set. atmos runs terraform init on its own before most commands, so the backend config is
+// passed to every init through the environment rather than as BackendConfig, which is only used by explicit inits.
+func SetLocalBackendOptions(config *Config, options *atmos.Options) {
+	if !config.LocalBackend || options.Component == "" || options.Stack == "" {
+		return
+	}
+
+	dir := LocalBackendDir(config, options.Component, options.Stack)
+	backendArgs := []string{
+		"-backend-config=" + quoteCLIArg("path="+filepath.Join(dir, "terraform.tfstate")),
+		"-backend-config=" + quoteCLIArg("workspace_dir="+dir),
+	}
+
+	// The fixtures need no backend config of their own, and workspace_key_prefix is not a setting of the local backend.
+	options.GenerateBackend = false
+	options.BackendConfig = map[string]interface{}{}
+	if options.EnvVars == nil {
+		options.EnvVars = map[string]string{}
+	}
+	options.EnvVars["TF_CLI_ARGS_init"] = strings.TrimSpace(options.EnvVars["TF_CLI_ARGS_init"] + " " + strings.Join(backendArgs, " "))
+}
+
+// quoteCLIArg quotes arg for TF_CLI_ARGS_*, which terraform splits like a shell command line, so that a path with
+// spaces stays a single argument.
+func quoteCLIArg(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,67 @@
+package lifecycle
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudposse/test-helpers/pkg/atmos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetLocalBackendOptions(t *testing.T) {
+	config := &Config{LocalBackend: true, StateDir: "/tmp/state"}
+	options := &atmos.Options{
+		Component:       "vpc",
+		Stack:           "default-test",
+		GenerateBackend: true,
+		BackendConfig:   map[string]interface{}{"workspace_key_prefix": "abc123-default-test"},
+		EnvVars:         map[string]string{"TF_CLI_ARGS_init": "-upgrade"},
+	}
+
+	SetLocalBackendOptions(config, options)
+	assert.False(t, options.GenerateBackend)
+	assert.Empty(t, options.BackendConfig)
+	assert.Equal(t,
+		"-upgrade -backend-config='path=/tmp/state/default-test/vpc/terraform.tfstate' -backend-config='workspace_dir=/tmp/state/default-test/vpc'",
+		options.EnvVars["TF_CLI_ARGS_init"])
+
+	// Commands that are not about a component, like vendor pull, are left alone.
+	options = &atmos.Options{GenerateBackend: true, EnvVars: map[string]string{}}
+	SetLocalBackendOptions(config, options)
+	assert.True(t, options.GenerateBackend)
+	assert.Empty(t, options.EnvVars)
+}
+
+func TestSetLocalBackendOptionsQuotesStateDir(t *testing.T) {
+	config := &Config{LocalBackend: true, StateDir: "/tmp/my state's dir"}
+	options := &atmos.Options{Component: "vpc", Stack: "test", EnvVars: map[string]string{}}
+
+	SetLocalBackendOptions(config, options)
+	assert.Equal(t,
+		`-backend-config='path=/tmp/my state'\''s dir/test/vpc/terraform.tfstate' -backend-config='workspace_dir=/tmp/my state'\''s dir/test/vpc'`,
+		options.EnvVars["TF_CLI_ARGS_init"])
+}
+
+func TestWriteLocalBackendOverrides(t *testing.T) {
+	componentsDir := t.TempDir()
+	for _, file := range []string{"target/main.tf", "target/modules/label/main.tf", "vpc/main.tf"} {
+		path := filepath.Join(componentsDir, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+	}
+
+	require.NoError(t, WriteLocalBackendOverrides(componentsDir))
+
+	for _, component := range []string{"target", "vpc"} {
+		content, err := os.ReadFile(filepath.Join(componentsDir, component, LocalBackendOverrideFile))
+		require.NoError(t, err)
+
+		var override map[string]map[string]map[string]interface{}
+		require.NoError(t, json.Unmarshal(content, &override))
+		assert.Contains(t, override["terraform"]["backend"], "local")
+	}
+	assert.NoFileExists(t, filepath.Join(componentsDir, "target", "modules", "label", LocalBackendOverrideFile))
+}
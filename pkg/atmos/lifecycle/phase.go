@@ -0,0 +1,147 @@
+package lifecycle
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// Phase is a step of the setup or teardown of a test suite. It runs as a subtest named after the phase.
+type Phase struct {
+	Name string
+	Run  func(t *testing.T)
+}
+
+// Phases are the setup and teardown phases of a test suite, in the order they run. The suites compose them from their
+// own phases and those of this package, and a test suite can add, replace or remove phases before they run.
+type Phases struct {
+	Setup    Pipeline
+	Teardown Pipeline
+}
+
+// SuiteSteps are the steps of a suite that differ between the helpers. SuitePhases composes them with the phases the
+// helpers share into the setup and teardown of the suite.
+type SuiteSteps struct {
+	ComponentsDir       string             // The terraform components directory of the atmos project, relative to the temp dir
+	Prepare             []Phase            // The setup phases that fill the temp dir, starting with bootstrapping it
+	BeforeDeploy        []Phase            // The setup phases that run once the temp dir is ready, before deploying
+	DeployDependencies  func(t *testing.T) // Deploys the dependencies of the suite
+	DestroyDependencies func(t *testing.T) // Destroys the dependencies of the suite, left out of the teardown if nil
+	DestroyTempDir      func(t *testing.T) // Removes the temp dir, Engine.DestroyTempDir if nil
+	DestroyLocalStack   func(t *testing.T) // Terminates the LocalStack container of the suite, if it has one
+}
+
+// SuitePhases returns the setup and teardown phases of a suite:
+//
+//   - setup: the Prepare phases, generating the local backends and pre-warming the plugin cache in ComponentsDir, the
+//     BeforeDeploy phases and deploying the dependencies
+//   - teardown: collecting diagnostics if the suite failed, destroying the dependencies, removing the temp dir and config
+//     file and terminating LocalStack, each unless the config skips it
+//
+// suiteT is the test of the whole suite, whose failure the diagnostics are collected for.
+func (e *Engine) SuitePhases(suiteT *testing.T, config *Config, steps SuiteSteps) *Phases {
+	phases := &Phases{}
+
+	// The temp dir is only known once the Prepare phases bootstrapped it, so the components dir is resolved when the
+	// phases run.
+	componentsDir := func() string { return filepath.Join(config.TempDir, steps.ComponentsDir) }
+
+	phases.Setup.Add(steps.Prepare...)
+	phases.Setup.Add(
+		Phase{Name: "setup/generate local backends", Run: func(t *testing.T) { e.GenerateLocalBackends(t, config, componentsDir()) }},
+		Phase{Name: "setup/prewarm plugin cache", Run: func(t *testing.T) { e.PrewarmPluginCache(t, config, componentsDir()) }},
+	)
+	phases.Setup.Add(steps.BeforeDeploy...)
+	phases.Setup.Add(Phase{Name: "deploy dependencies", Run: steps.DeployDependencies})
+
+	if config.DiagnosticsDir != "" {
+		phases.Teardown.Add(Phase{Name: "teardown/collect diagnostics", Run: func(t *testing.T) {
+			e.CollectFailedSuiteDiagnostics(t, suiteT, config)
+		}})
+	}
+	if steps.DestroyDependencies != nil && !config.SkipDestroyDependencies {
+		phases.Teardown.Add(Phase{Name: "destroy dependencies", Run: steps.DestroyDependencies})
+	}
+	if !config.SkipTeardownTestSuite {
+		destroyTempDir := steps.DestroyTempDir
+		if destroyTempDir == nil {
+			destroyTempDir = func(t *testing.T) { e.DestroyTempDir(t, config) }
+		}
+		phases.Teardown.Add(
+			Phase{Name: "teardown/destroy temp dir", Run: destroyTempDir},
+			Phase{Name: "teardown/destroy config file", Run: func(t *testing.T) { e.DestroyConfigFile(t, config) }},
+		)
+	}
+	if steps.DestroyLocalStack != nil && !config.SkipTearDownLocalStack {
+		phases.Teardown.Add(Phase{Name: "teardown/destroy localstack container", Run: steps.DestroyLocalStack})
+	}
+
+	return phases
+}
+
+// Pipeline is a list of phases that run in order.
+type Pipeline []Phase
+
+// Index returns the index of the phase with the given name, or -1 if there is none.
+func (p Pipeline) Index(name string) int {
+	for i, phase := range p {
+		if phase.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Add appends phases to the end of the pipeline.
+func (p *Pipeline) Add(phases ...Phase) {
+	*p = append(*p, phases...)
+}
+
+// InsertBefore inserts phases before the phase with the given name. A PhaseNotFound error is returned if there is no
+// such phase.
+func (p *Pipeline) InsertBefore(name string, phases ...Phase) error {
+	i := p.Index(name)
+	if i < 0 {
+		return PhaseNotFound(name)
+	}
+	p.insert(i, phases)
+	return nil
+}
+
+// InsertAfter inserts phases after the phase with the given name. A PhaseNotFound error is returned if there is no such
+// phase.
+func (p *Pipeline) InsertAfter(name string, phases ...Phase) error {
+	i := p.Index(name)
+	if i < 0 {
+		return PhaseNotFound(name)
+	}
+	p.insert(i+1, phases)
+	return nil
+}
+
+// Replace replaces the phase with the given name. A PhaseNotFound error is returned if there is no such phase.
+func (p *Pipeline) Replace(name string, phase Phase) error {
+	i := p.Index(name)
+	if i < 0 {
+		return PhaseNotFound(name)
+	}
+	(*p)[i] = phase
+	return nil
+}
+
+// Remove removes the phase with the given name. A PhaseNotFound error is returned if there is no such phase.
+func (p *Pipeline) Remove(name string) error {
+	i := p.Index(name)
+	if i < 0 {
+		return PhaseNotFound(name)
+	}
+	*p = append((*p)[:i], (*p)[i+1:]...)
+	return nil
+}
+
+func (p *Pipeline) insert(i int, phases []Phase) {
+	inserted := make(Pipeline, 0, len(*p)+len(phases))
+	inserted = append(inserted, (*p)[:i]...)
+	inserted = append(inserted, phases...)
+	inserted = append(inserted, (*p)[i:]...)
+	*p = inserted
+}
@@ -0,0 +1,73 @@
+package lifecycle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func phaseNames(p Pipeline) []string {
+	names := make([]string, 0, len(p))
+	for _, phase := range p {
+		names = append(names, phase.Name)
+	}
+	return names
+}
+
+func TestPipelineInsertReplaceAndRemove(t *testing.T) {
+	var p Pipeline
+	p.Add(Phase{Name: "bootstrap"}, Phase{Name: "deploy dependencies"})
+
+	require.NoError(t, p.InsertBefore("deploy dependencies", Phase{Name: "setup/validate stacks"}))
+	require.NoError(t, p.InsertAfter("bootstrap", Phase{Name: "vendor"}))
+	assert.Equal(t, []string{"bootstrap", "vendor", "setup/validate stacks", "deploy dependencies"}, phaseNames(p))
+
+	require.NoError(t, p.Replace("vendor", Phase{Name: "pull"}))
+	require.NoError(t, p.Remove("setup/validate stacks"))
+	assert.Equal(t, []string{"bootstrap", "pull", "deploy dependencies"}, phaseNames(p))
+	assert.Equal(t, 2, p.Index("deploy dependencies"))
+	assert.Equal(t, -1, p.Index("vendor"))
+}
+
+func TestPipelineFailsOnUnknownPhase(t *testing.T) {
+	p := Pipeline{{Name: "bootstrap"}}
+
+	assert.Equal(t, PhaseNotFound("vendor"), p.InsertBefore("vendor", Phase{Name: "pull"}))
+	assert.Equal(t, PhaseNotFound("vendor"), p.InsertAfter("vendor", Phase{Name: "pull"}))
+	assert.Equal(t, PhaseNotFound("vendor"), p.Replace("vendor", Phase{Name: "pull"}))
+	assert.Equal(t, PhaseNotFound("vendor"), p.Remove("vendor"))
+	assert.Equal(t, []string{"bootstrap"}, phaseNames(p))
+}
+
+func TestSuitePhases(t *testing.T) {
+	var e Engine
+	run := func(t *testing.T) {}
+	steps := SuiteSteps{
+		ComponentsDir:       "components/terraform",
+		Prepare:             []Phase{{Name: "setup/bootstrap temp dir", Run: run}},
+		BeforeDeploy:        []Phase{{Name: "setup/validate stacks", Run: run}},
+		DeployDependencies:  run,
+		DestroyDependencies: run,
+		DestroyLocalStack:   run,
+	}
+
+	phases := e.SuitePhases(t, &Config{DiagnosticsDir: t.TempDir()}, steps)
+	assert.Equal(t, []string{
+		"setup/bootstrap temp dir",
+		"setup/generate local backends",
+		"setup/prewarm plugin cache",
+		"setup/validate stacks",
+		"deploy dependencies",
+	}, phaseNames(phases.Setup))
+	assert.Equal(t, []string{
+		"teardown/collect diagnostics",
+		"destroy dependencies",
+		"teardown/destroy temp dir",
+		"teardown/destroy config file",
+		"teardown/destroy localstack container",
+	}, phaseNames(phases.Teardown))
+
+	phases = e.SuitePhases(t, &Config{SkipDestroyDependencies: true, SkipTeardownTestSuite: true, SkipTearDownLocalStack: true}, steps)
+	assert.Empty(t, phases.Teardown)
+}
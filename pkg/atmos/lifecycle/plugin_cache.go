@@ -0,0 +1,69 @@
+package lifecycle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	log "github.com/charmbracelet/log"
+	"github.com/cloudposse/test-helpers/pkg/atmos"
+	"github.com/cloudposse/test-helpers/pkg/atmos/plugincache"
+	"github.com/stretchr/testify/require"
+)
+
+// pluginCacheLockTimeout is how long to wait for other test packages to finish pre-warming the plugin cache.
+const pluginCacheLockTimeout = 30 * time.Minute
+
+// InitPluginCache creates the shared terraform plugin cache and sets Config.PluginCacheDir to it. No cache is used if
+// it is skipped or if providers are installed from Config.PluginDir.
+func InitPluginCache(t *testing.T, config *Config) {
+	if config.SkipPluginCache || config.PluginDir != "" {
+		return
+	}
+
+	cache, err := plugincache.New(config.PluginCacheDir)
+	require.NoError(t, err)
+	config.PluginCacheDir = cache.Dir
+}
+
+// PrewarmPluginCache downloads the providers of every component under componentsDir into the plugin cache, unless an
+// earlier suite already did, see plugincache.Cache.Prewarm. The cache only speeds up the suite, so a failure to
+// pre-warm it is logged rather than failing the suite.
+func (e *Engine) PrewarmPluginCache(t *testing.T, config *Config, componentsDir string) {
+	const phaseName = "setup/prewarm plugin cache"
+	if config.SkipSetupTestSuite || config.SkipPluginCache || config.PluginDir != "" || config.PluginCacheDir == "" {
+		e.LogPhaseStatus(t, phaseName, "skipped")
+		return
+	}
+
+	e.LogPhaseStatus(t, phaseName, "started")
+
+	ctx, cancel := context.WithTimeout(context.Background(), pluginCacheLockTimeout)
+	defer cancel()
+
+	cache := &plugincache.Cache{Dir: config.PluginCacheDir}
+	if err := cache.Prewarm(ctx, t, componentsDir); err != nil {
+		log.WithPrefix(t.Name()).Warn("failed to pre-warm the plugin cache, providers will be downloaded by each component", "path", config.PluginCacheDir, "error", err)
+	}
+
+	e.LogPhaseStatus(t, phaseName, "completed")
+}
+
+// SetPluginOptions makes atmos install providers from Config.PluginDir, or else use the shared plugin cache.
+func SetPluginOptions(config *Config, options *atmos.Options) {
+	if config.PluginDir != "" {
+		options.PluginDir = config.PluginDir
+		return
+	}
+	if config.SkipPluginCache || config.PluginCacheDir == "" {
+		return
+	}
+
+	if options.EnvVars == nil {
+		options.EnvVars = map[string]string{}
+	}
+	cache := &plugincache.Cache{Dir: config.PluginCacheDir}
+	for key, value := range cache.EnvVars() {
+		options.EnvVars[key] = value
+	}
+}
@@ -0,0 +1,161 @@
+package lifecycle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	log "github.com/charmbracelet/log"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+// CopyDirectoryContents copies every file and directory in srcDir to destDir.
+func CopyDirectoryContents(srcDir string, destDir string) error {
+	// Walk through all files and directories in srcDir and copy them to destDir
+	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Calculate the relative path from srcDir
+		relPath, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+
+		// Calculate destination path in destDir
+		destPath := filepath.Join(destDir, relPath)
+
+		if info.IsDir() {
+			// Create directory in destination
+			return os.MkdirAll(destPath, info.Mode())
+		}
+
+		// Copy file content
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(destPath, content, info.Mode())
+	})
+}
+
+// CopyDirectoryRecursively copies srcDir to destDir, logging the copy as skipped if either of them does not exist.
+func (e *Engine) CopyDirectoryRecursively(t *testing.T, srcDir string, destDir string) {
+	e.copyDirectory(t, srcDir, destDir, func() error {
+		return CopyDirectoryContents(srcDir, destDir)
+	})
+}
+
+// copyDirectory runs copyFn to copy srcDir to destDir, logging the copy as skipped if either of them does not exist.
+func (e *Engine) copyDirectory(t *testing.T, srcDir string, destDir string, copyFn func() error) {
+	_, err := os.Stat(srcDir)
+	if os.IsNotExist(err) {
+		path := fmt.Sprintf("setup/copy %s to %s, source directory does not exist", srcDir, destDir)
+		e.LogPhaseStatus(t, path, "skipped")
+		return
+	}
+
+	_, err = os.Stat(destDir)
+	if os.IsNotExist(err) {
+		path := fmt.Sprintf("setup/copy %s to %s, destination directory does not exist", srcDir, destDir)
+		e.LogPhaseStatus(t, path, "skipped")
+		return
+	}
+
+	log.Debug("copying directory recursively", "srcDir", srcDir, "destDir", destDir)
+
+	err = copyFn()
+	require.NoError(t, err)
+}
+
+// SetTempDir creates a random temp dir for the suite unless Config.TempDir is set, and writes it to the config file.
+func SetTempDir(t *testing.T, config *Config) {
+	if config.TempDir == "" {
+		tempDir, err := os.MkdirTemp("", "atmos-test-helper")
+		require.NoError(t, err)
+
+		viper.Set("TempDir", tempDir)
+		config.TempDir = tempDir
+
+		err = config.WriteConfig()
+		require.NoError(t, err)
+	}
+
+	log.WithPrefix(t.Name()).Info("tests will be run in temp directory", "path", config.TempDir)
+}
+
+// SetStateDir creates the state dir in the temp dir unless Config.StateDir is set, and writes it to the config file.
+func SetStateDir(t *testing.T, config *Config) {
+	if config.StateDir == "" {
+		stateDir := filepath.Join(config.TempDir, "state")
+		viper.Set("StateDir", stateDir)
+		config.StateDir = stateDir
+
+		err := os.MkdirAll(stateDir, 0755)
+		require.NoError(t, err)
+
+		err = config.WriteConfig()
+		require.NoError(t, err)
+	}
+
+	log.WithPrefix(t.Name()).Info("terraform state for tests will be saved in state directory", "path", config.StateDir)
+}
+
+// SetAtmosPaths creates the components and stacks directories of the atmos project in the temp dir.
+func SetAtmosPaths(t *testing.T, config *Config) {
+	componentsDir := filepath.Join(config.TempDir, "components", "terraform")
+	log.WithPrefix(t.Name()).Debug("creating atmos terraform components directory", "path", componentsDir)
+	err := os.MkdirAll(componentsDir, 0755)
+	require.NoError(t, err)
+
+	stacksDir := filepath.Join(config.TempDir, "stacks")
+	log.WithPrefix(t.Name()).Debug("creating atmos terraform stacks directory", "path", stacksDir)
+	err = os.MkdirAll(stacksDir, 0755)
+	require.NoError(t, err)
+}
+
+// DestroyTempDir removes the state dir and the temp dir, unless a phase is skipped and a later run may need them.
+func (e *Engine) DestroyTempDir(t *testing.T, config *Config) {
+	const phaseName = "teardown/destroy temp dir"
+
+	if config.AnyPhasesSkipped() {
+		e.LogPhaseStatus(t, phaseName, "skipped")
+		return
+	}
+
+	e.LogPhaseStatus(t, phaseName, "started")
+
+	log.WithPrefix(t.Name()).Info("removing terraform state directory", "path", config.StateDir)
+	err := os.RemoveAll(config.StateDir)
+	require.NoError(t, err)
+
+	log.WithPrefix(t.Name()).Info("removing temp directory", "path", config.TempDir)
+	err = os.RemoveAll(config.TempDir)
+	require.NoError(t, err)
+
+	e.LogPhaseStatus(t, phaseName, "completed")
+}
+
+// DestroyConfigFile removes the config file, unless a phase is skipped and a later run may need it.
+func (e *Engine) DestroyConfigFile(t *testing.T, config *Config) {
+	const phaseName = "teardown/destroy config file"
+
+	if config.AnyPhasesSkipped() {
+		e.LogPhaseStatus(t, phaseName, "skipped")
+		return
+	}
+
+	e.LogPhaseStatus(t, phaseName, "started")
+
+	err := os.Remove(config.ConfigFilePath)
+	if err != nil {
+		e.LogPhaseStatus(t, phaseName, "failed")
+		require.NoError(t, err)
+	}
+
+	e.LogPhaseStatus(t, phaseName, "completed")
+}
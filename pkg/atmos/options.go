@@ -12,29 +12,33 @@ import (
 )
 
 var (
-	DefaultRetryableAtmosErrors = map[string]string{
+	// defaultRetryableErrors are the errors retried by DefaultRetryPolicy, in the order they are matched. The entries
+	// for eventual consistency errors retry more often than the others, since those take a while to resolve.
+	defaultRetryableErrors = []RetryableError{
 		// Helm related atmos calls may fail when too many tests run in parallel. While the exact cause is unknown,
 		// this is presumably due to all the network contention involved. Usually a retry resolves the issue.
-		".*read: connection reset by peer.*": "Failed to reach helm charts repository.",
-		".*transport is closing.*":           "Failed to reach Kubernetes API.",
+		{Pattern: ".*read: connection reset by peer.*", Message: "Failed to reach helm charts repository."},
+		{Pattern: ".*transport is closing.*", Message: "Failed to reach Kubernetes API."},
 
 		// `atmos init` frequently fails in CI due to network issues accessing plugins. The reason is unknown, but
 		// eventually these succeed after a few retries.
-		".*unable to verify signature.*":                  "Failed to retrieve plugin due to transient network error.",
-		".*unable to verify checksum.*":                   "Failed to retrieve plugin due to transient network error.",
-		".*no provider exists with the given name.*":      "Failed to retrieve plugin due to transient network error.",
-		".*registry service is unreachable.*":             "Failed to retrieve plugin due to transient network error.",
-		".*Error installing provider.*":                   "Failed to retrieve plugin due to transient network error.",
-		".*Failed to query available provider packages.*": "Failed to retrieve plugin due to transient network error.",
-		".*timeout while waiting for plugin to start.*":   "Failed to retrieve plugin due to transient network error.",
-		".*timed out waiting for server handshake.*":      "Failed to retrieve plugin due to transient network error.",
-		"could not query provider registry for":           "Failed to retrieve plugin due to transient network error.",
+		{Pattern: ".*unable to verify signature.*", Message: "Failed to retrieve plugin due to transient network error."},
+		{Pattern: ".*unable to verify checksum.*", Message: "Failed to retrieve plugin due to transient network error."},
+		{Pattern: ".*no provider exists with the given name.*", Message: "Failed to retrieve plugin due to transient network error."},
+		{Pattern: ".*registry service is unreachable.*", Message: "Failed to retrieve plugin due to transient network error."},
+		{Pattern: ".*Error installing provider.*", Message: "Failed to retrieve plugin due to transient network error."},
+		{Pattern: ".*Failed to query available provider packages.*", Message: "Failed to retrieve plugin due to transient network error."},
+		{Pattern: ".*timeout while waiting for plugin to start.*", Message: "Failed to retrieve plugin due to transient network error."},
+		{Pattern: ".*timed out waiting for server handshake.*", Message: "Failed to retrieve plugin due to transient network error."},
+		{Pattern: "could not query provider registry for", Message: "Failed to retrieve plugin due to transient network error."},
 
 		// Provider bugs where the data after apply is not propagated. This is usually an eventual consistency issue, so
 		// retrying should self resolve it.
 		// See https://github.com/atmos-providers/atmos-provider-aws/issues/12449 for an example.
-		".*Provider produced inconsistent result after apply.*": "Provider eventual consistency error.",
+		{Pattern: ".*Provider produced inconsistent result after apply.*", Message: "Provider eventual consistency error.", MaxRetries: eventualConsistencyMaxRetries},
 	}
+
+	DefaultRetryableAtmosErrors = retryableErrorMessages(defaultRetryableErrors)
 )
 
 // Options for running Atmos commands
@@ -62,6 +66,8 @@ type Options struct {
 	RetryableAtmosErrors      map[string]string      // If Atmos apply fails with one of these (transient) errors, retry. The keys are a regexp to match against the error and the message is what to display to a user if that error is matched.
 	MaxRetries                int                    // Maximum number of times to retry errors matching RetryableAtmosErrors
 	TimeBetweenRetries        time.Duration          // The amount of time to wait between retries
+	RetryPolicy               *RetryPolicy           // Retries with backoff and jitter. If set, RetryableAtmosErrors, MaxRetries and TimeBetweenRetries are ignored.
+	Timeout                   time.Duration          // The maximum amount of time a single run of atmos may take before it is killed, no limit if zero
 	Upgrade                   bool                   // Whether the -upgrade flag of the atmos init command should be set to true or not
 	Reconfigure               bool                   // Set the -reconfigure flag to the atmos init command
 	MigrateState              bool                   // Set the -migrate-state and -force-copy (suppress 'yes' answer prompt) flag to the atmos init command
@@ -72,9 +78,11 @@ type Options struct {
 	NoStderr                  bool                   // Disable stderr redirection
 	OutputMaxLineSize         int                    // The max size of one line in stdout and stderr (in bytes)
 	Logger                    *logger.Logger         // Set a non-default logger that should be used. See the logger package for more info.
+	Recorder                  CommandRecorder        // Receives a record of every atmos command run with these options, e.g. for a run report
+	Executor                  Executor               // Runs the atmos commands, ShellExecutor if nil. Set a FakeExecutor to test without atmos.
 	Parallelism               int                    // Set the parallelism setting for Atmos
 	PlanFilePath              string                 // The path to output a plan file to (for the plan command) or read one from (for the apply command)
-	PluginDir                 string                 // The path of downloaded plugins to pass to the atmos init command (-plugin-dir)
+	PluginDir                 string                 // The path of downloaded plugins to pass to every terraform init (-plugin-dir), e.g. a local provider mirror for offline runs
 	SetVarsAfterVarFiles      bool                   // Pass -var options after -var-file options to Atmos commands
 	WarningsAsErrors          map[string]string      // Terraform warning messages that should be treated as errors. The keys are a regexp to match against the warning and the value is what to display to a user if that warning is matched.
 	VendorComponent           string                 // The component to pass to the atmos vendor command, if not passed all components will be vendored
@@ -109,6 +117,7 @@ func (options *Options) Clone() (*Options, error) {
 	for key, val := range options.RetryableAtmosErrors {
 		newOptions.RetryableAtmosErrors[key] = val
 	}
+	newOptions.RetryPolicy = options.RetryPolicy.Clone()
 
 	return newOptions, nil
 }
@@ -140,8 +149,9 @@ func isZeroValue(v reflect.Value) bool {
 
 // WithDefaultRetryableErrors makes a copy of the Options object and returns an updated object with sensible defaults
 // for retryable errors. The included retryable errors are typical errors that most atmos modules encounter during
-// testing, and are known to self resolve upon retrying. This will fail the test if there are any errors in the cloning
-// process.
+// testing, and are known to self resolve upon retrying. If neither RetryPolicy nor MaxRetries are set, i.e. MaxRetries
+// is negative, the copy retries them with DefaultRetryPolicy, so that parallel tests back off with jitter. This will
+// fail the test if there are any errors in the cloning process.
 func WithDefaultRetryableErrors(t testing.TestingT, originalOptions *Options) *Options {
 	newOptions, err := originalOptions.Clone()
 	require.NoError(t, err)
@@ -157,6 +167,13 @@ func WithDefaultRetryableErrors(t testing.TestingT, originalOptions *Options) *O
 	if originalOptions.MaxRetries < 0 {
 		newOptions.MaxRetries = 3
 		newOptions.TimeBetweenRetries = 5 * time.Second
+
+		if newOptions.RetryPolicy == nil {
+			newOptions.RetryPolicy = DefaultRetryPolicy()
+			if len(originalOptions.RetryableAtmosErrors) > 0 {
+				newOptions.RetryPolicy.Errors = retryableErrors(originalOptions.RetryableAtmosErrors)
+			}
+		}
 	}
 
 	return newOptions
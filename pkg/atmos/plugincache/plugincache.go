@@ -0,0 +1,244 @@
+// Package plugincache manages a terraform provider plugin cache that is shared by test suites, so that providers are
+// downloaded once instead of in the fresh temp dir of every suite.
+package plugincache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cloudposse/test-helpers/pkg/atmos"
+	tt "github.com/cloudposse/test-helpers/pkg/testing"
+	"github.com/gofrs/flock"
+	"github.com/gruntwork-io/terratest/modules/files"
+	"github.com/gruntwork-io/terratest/modules/shell"
+)
+
+const (
+	// EnvCacheDir is the environment variable terraform reads the plugin cache directory from.
+	EnvCacheDir = "TF_PLUGIN_CACHE_DIR"
+
+	// EnvMayBreakDependencyLockFile makes terraform use cached providers even if the dependency lock file of a
+	// component does not have their checksums for every platform.
+	EnvMayBreakDependencyLockFile = "TF_PLUGIN_CACHE_MAY_BREAK_DEPENDENCY_LOCK_FILE"
+
+	lockFileName   = ".test-helpers.lock"
+	markersDirName = ".test-helpers-prewarmed"
+	lockRetryDelay = time.Second
+)
+
+// Cache is a terraform provider plugin cache. Terraform does not guard the cache against concurrent writes, so it is
+// pre-warmed under a file lock that test packages running in parallel wait for if it is not warm yet.
+type Cache struct {
+	Dir             string // The directory providers are cached in
+	TerraformBinary string // The terraform binary used to pre-warm the cache, "terraform" if empty
+}
+
+// DefaultDir returns the directory of the cache shared by every test suite of the user.
+func DefaultDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "cloudposse-test-helpers", "terraform-plugin-cache"), nil
+}
+
+// New creates the cache in dir, or in DefaultDir if dir is empty.
+func New(dir string) (*Cache, error) {
+	if dir == "" {
+		var err error
+		if dir, err = DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating plugin cache %s: %w", dir, err)
+	}
+	return &Cache{Dir: dir}, nil
+}
+
+// EnvVars returns the environment variables that make terraform use the cache.
+func (c *Cache) EnvVars() map[string]string {
+	return map[string]string{
+		EnvCacheDir:                   c.Dir,
+		EnvMayBreakDependencyLockFile: "true",
+	}
+}
+
+// Lock takes the lock of the cache, waiting until ctx is done for other test packages to release it. The returned
+// function releases the lock.
+func (c *Cache) Lock(ctx context.Context) (func() error, error) {
+	lock := flock.New(filepath.Join(c.Dir, lockFileName))
+	locked, err := lock.TryLockContext(ctx, lockRetryDelay)
+	if err != nil {
+		return nil, fmt.Errorf("locking plugin cache %s: %w", c.Dir, err)
+	}
+	if !locked {
+		return nil, fmt.Errorf("locking plugin cache %s: lock not acquired", c.Dir)
+	}
+	return lock.Unlock, nil
+}
+
+// Prewarm downloads the providers of the components under componentsDir into the cache. Each component is
+// initialized once: a marker named after its terraform files is written into the cache after it was, so that later
+// suites find the cache warm without waiting for the lock of the cache. The components are initialized in a copy of
+// componentsDir, which is removed afterwards, so that componentsDir is left without .terraform directories and lock
+// files.
+func (c *Cache) Prewarm(ctx context.Context, t tt.TestingT, componentsDir string) error {
+	pending, err := c.pendingComponents(componentsDir)
+	if err != nil || len(pending) == 0 {
+		return err
+	}
+
+	unlock, err := c.Lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Another test package may have pre-warmed the cache while this one waited for the lock.
+	if pending, err = c.pendingComponents(componentsDir); err != nil || len(pending) == 0 {
+		return err
+	}
+
+	copyDir, err := files.CopyTerraformFolderToTemp(componentsDir, "plugin-cache-prewarm")
+	if err != nil {
+		return fmt.Errorf("copying %s to pre-warm the plugin cache: %w", componentsDir, err)
+	}
+	defer os.RemoveAll(filepath.Dir(copyDir))
+
+	binary := c.TerraformBinary
+	if binary == "" {
+		binary = "terraform"
+	}
+
+	for dir, marker := range pending {
+		relPath, err := filepath.Rel(componentsDir, dir)
+		if err != nil {
+			return err
+		}
+
+		_, err = atmos.ShellExecutor{}.Execute(ctx, t, shell.Command{
+			Command:    binary,
+			Args:       []string{"init", "-backend=false", "-input=false", "-no-color"},
+			WorkingDir: filepath.Join(copyDir, relPath),
+			Env:        c.EnvVars(),
+		})
+		if err != nil {
+			return fmt.Errorf("pre-warming plugin cache with %s: %w", dir, err)
+		}
+		if err := os.WriteFile(marker, nil, 0644); err != nil {
+			return fmt.Errorf("marking %s as pre-warmed: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// pendingComponents returns the components under componentsDir that the cache was not pre-warmed with yet, mapped to
+// the path of the marker to write once it was.
+func (c *Cache) pendingComponents(componentsDir string) (map[string]string, error) {
+	componentDirs, err := ComponentDirs(componentsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	markersDir := filepath.Join(c.Dir, markersDirName)
+	if err := os.MkdirAll(markersDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating plugin cache markers %s: %w", markersDir, err)
+	}
+
+	pending := map[string]string{}
+	for _, dir := range componentDirs {
+		key, err := componentKey(dir)
+		if err != nil {
+			return nil, err
+		}
+		marker := filepath.Join(markersDir, key)
+		if _, err := os.Stat(marker); os.IsNotExist(err) {
+			pending[dir] = marker
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return pending, nil
+}
+
+// componentKey returns a hash of the terraform files and the dependency lock file of the component, which changes
+// whenever the providers it requires may have changed.
+func componentKey(dir string) (string, error) {
+	hash := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".terraform" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".tf") && !strings.HasSuffix(d.Name(), ".tf.json") && d.Name() != ".terraform.lock.hcl" {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(hash, "%s\x00%d\x00", filepath.ToSlash(relPath), len(content))
+		hash.Write(content)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// ComponentDirs returns the terraform components under root, which are the directories that contain .tf files. The
+// directories inside a component, such as its local modules, are not returned.
+func ComponentDirs(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if d.Name() == ".terraform" {
+			return filepath.SkipDir
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".tf") {
+				dirs = append(dirs, path)
+				return filepath.SkipDir
+			}
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return dirs, err
+}
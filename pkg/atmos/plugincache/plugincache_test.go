@@ -0,0 +1,117 @@
+package plugincache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCreatesCacheDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plugin-cache")
+
+	cache, err := New(dir)
+	require.NoError(t, err)
+	assert.DirExists(t, dir)
+	assert.Equal(t, map[string]string{
+		EnvCacheDir:                   dir,
+		EnvMayBreakDependencyLockFile: "true",
+	}, cache.EnvVars())
+}
+
+func TestLockIsExclusive(t *testing.T) {
+	cache, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	unlock, err := cache.Lock(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = cache.Lock(ctx)
+	assert.Error(t, err)
+
+	require.NoError(t, unlock())
+	unlock, err = cache.Lock(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, unlock())
+}
+
+func TestComponentDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{
+		"vpc/main.tf",
+		"vpc/modules/subnets/main.tf",
+		"vpc/.terraform/modules/label/main.tf",
+		"account-map/modules/iam-roles/main.tf",
+		"README.md",
+	} {
+		path := filepath.Join(root, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+	}
+
+	dirs, err := ComponentDirs(root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(root, "account-map", "modules", "iam-roles"),
+		filepath.Join(root, "vpc"),
+	}, dirs)
+
+	dirs, err = ComponentDirs(filepath.Join(root, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, dirs)
+}
+
+func TestPrewarm(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script in place of terraform")
+	}
+
+	// The fake terraform records the component it was run in and the plugin cache it was given.
+	terraform := filepath.Join(t.TempDir(), "terraform")
+	script := "#!/bin/sh\necho \"$PWD\" >> \"$TF_PLUGIN_CACHE_DIR/prewarmed\"\n"
+	require.NoError(t, os.WriteFile(terraform, []byte(script), 0755))
+
+	cache, err := New(t.TempDir())
+	require.NoError(t, err)
+	cache.TerraformBinary = terraform
+
+	componentsDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(componentsDir, "vpc"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(componentsDir, "vpc", "main.tf"), []byte("terraform {}\n"), 0644))
+	require.NoError(t, cache.Prewarm(context.Background(), t, componentsDir))
+
+	prewarmed, err := os.ReadFile(filepath.Join(cache.Dir, "prewarmed"))
+	require.NoError(t, err)
+	lines := strings.Fields(string(prewarmed))
+	require.Len(t, lines, 1)
+	assert.Equal(t, "vpc", filepath.Base(lines[0]))
+	assert.NotEqual(t, filepath.Join(componentsDir, "vpc"), lines[0], "terraform init runs in a copy of the component")
+	assert.NoDirExists(t, filepath.Dir(filepath.Dir(lines[0])), "the copy is removed")
+
+	// Once the cache is warm, it is neither initialized again nor locked.
+	unlock, err := cache.Lock(context.Background())
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.NoError(t, cache.Prewarm(ctx, t, componentsDir))
+	require.NoError(t, unlock())
+
+	prewarmed, err = os.ReadFile(filepath.Join(cache.Dir, "prewarmed"))
+	require.NoError(t, err)
+	assert.Len(t, strings.Fields(string(prewarmed)), 1)
+
+	// A component whose terraform files changed is initialized again.
+	require.NoError(t, os.WriteFile(filepath.Join(componentsDir, "vpc", "versions.tf"), []byte("terraform {}\n"), 0644))
+	require.NoError(t, cache.Prewarm(context.Background(), t, componentsDir))
+	prewarmed, err = os.ReadFile(filepath.Join(cache.Dir, "prewarmed"))
+	require.NoError(t, err)
+	assert.Len(t, strings.Fields(string(prewarmed)), 2)
+}
@@ -0,0 +1,159 @@
+package atmos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	tt "github.com/cloudposse/test-helpers/pkg/testing"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/shell"
+)
+
+// processWaitDelay is how long to wait for the output of a killed command to be closed, in case a process that is not
+// part of its process group holds it open.
+const processWaitDelay = 10 * time.Second
+
+// lineOutput holds the output of a command, line by line, per stream and merged in the order it was written.
+type lineOutput struct {
+	mu       sync.Mutex
+	stdout   []string
+	stderr   []string
+	combined []string
+}
+
+func (o *lineOutput) append(stream *[]string, line string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	*stream = append(*stream, line)
+	o.combined = append(o.combined, line)
+}
+
+func (o *lineOutput) Stdout() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return strings.Join(o.stdout, "\n")
+}
+
+func (o *lineOutput) Stderr() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return strings.Join(o.stderr, "\n")
+}
+
+func (o *lineOutput) Combined() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return strings.Join(o.combined, "\n")
+}
+
+func (o *lineOutput) result() CommandOutput {
+	return CommandOutput{Stdout: o.Stdout(), Stderr: o.Stderr(), Combined: o.Combined()}
+}
+
+// lineWriter logs every line written to it and appends it to a stream of the command output.
+type lineWriter struct {
+	t       tt.TestingT
+	logger  *logger.Logger
+	output  *lineOutput
+	stream  *[]string
+	partial []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		w.writeLine(string(w.partial[:i]))
+		w.partial = w.partial[i+1:]
+	}
+}
+
+// flush writes the last line, if the command did not end its output with a newline.
+func (w *lineWriter) flush() {
+	if len(w.partial) > 0 {
+		w.writeLine(string(w.partial))
+		w.partial = nil
+	}
+}
+
+func (w *lineWriter) writeLine(line string) {
+	// The format string keeps formatting characters in the line from being interpreted.
+	w.logger.Logf(w.t, "%s", line)
+	w.output.append(w.stream, line)
+}
+
+// runCommand runs the command like terratest's shell package does, logging every line of its output, but in a process
+// group of its own that is killed when ctx is done. It returns the context error if ctx is done before the command
+// exits, or a CommandFailed error if the command fails.
+func runCommand(ctx context.Context, t tt.TestingT, command shell.Command) (*lineOutput, error) {
+	command.Logger.Logf(t, "Running command %s with args %s", command.Command, command.Args)
+
+	output := &lineOutput{}
+	stdout := &lineWriter{t: t, logger: command.Logger, output: output, stream: &output.stdout}
+	stderr := &lineWriter{t: t, logger: command.Logger, output: output, stream: &output.stderr}
+
+	cmd := exec.CommandContext(ctx, command.Command, command.Args...)
+	cmd.Dir = command.WorkingDir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = formatEnvVars(command)
+	cmd.WaitDelay = processWaitDelay
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		return killProcessGroup(cmd)
+	}
+
+	err := cmd.Run()
+	stdout.flush()
+	stderr.flush()
+
+	if ctx.Err() != nil {
+		return output, ctx.Err()
+	}
+	if err != nil {
+		return output, &CommandFailed{Underlying: err, Stdout: output.Stdout(), Stderr: output.Stderr()}
+	}
+	return output, nil
+}
+
+// exitCoder is implemented by the errors of commands that exited with a non-zero exit code, such as *exec.ExitError.
+type exitCoder interface {
+	ExitCode() int
+}
+
+// exitCodeForError returns the exit code of the command that returned err. Errors other than the command exiting with
+// a non-zero exit code, e.g. the command not being found, are returned.
+func exitCodeForError(err error) (int, error) {
+	if err == nil {
+		return DefaultSuccessExitCode, nil
+	}
+
+	var exitErr exitCoder
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	return DefaultErrorExitCode, err
+}
+
+func formatEnvVars(command shell.Command) []string {
+	env := os.Environ()
+	for key, value := range command.Env {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+	return env
+}
@@ -0,0 +1,77 @@
+//go:build !windows
+
+package atmos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunAtmosCommandETimeoutKillsProcessGroup(t *testing.T) {
+	options := &Options{
+		AtmosBinary:   "sh",
+		AtmosBasePath: t.TempDir(),
+		Timeout:       500 * time.Millisecond,
+	}
+
+	startedAt := time.Now()
+	// The background sleep holds on to the output of the shell, so the command only returns early if it is killed too.
+	out, err := RunAtmosCommandE(t, options, "-c", "echo started; sleep 30 & sleep 30")
+	require.Error(t, err)
+	assert.Less(t, time.Since(startedAt), 5*time.Second)
+	assert.Contains(t, out, "started")
+
+	var timedOut CommandTimedOut
+	require.True(t, errors.As(err, &timedOut))
+	assert.Equal(t, options.Timeout, timedOut.Timeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "timed out after 500ms")
+}
+
+func TestRunAtmosCommandContextECancelled(t *testing.T) {
+	options := &Options{
+		AtmosBinary:          "sh",
+		AtmosBasePath:        t.TempDir(),
+		RetryableAtmosErrors: map[string]string{".*": "any error"},
+		MaxRetries:           3,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	startedAt := time.Now()
+	_, err := RunAtmosCommandContextE(ctx, t, options, "-c", "sleep 30")
+	require.Error(t, err)
+	assert.Less(t, time.Since(startedAt), 5*time.Second)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	var timedOut CommandTimedOut
+	require.True(t, errors.As(err, &timedOut))
+	assert.Zero(t, timedOut.Timeout)
+}
+
+func TestRunAtmosCommandEWithinTimeout(t *testing.T) {
+	options := &Options{
+		AtmosBinary:   "sh",
+		AtmosBasePath: t.TempDir(),
+		Timeout:       10 * time.Second,
+	}
+
+	out, err := RunAtmosCommandE(t, options, "-c", "echo out; echo err >&2")
+	require.NoError(t, err)
+	assert.Contains(t, out, "out")
+	assert.Contains(t, out, "err")
+
+	out, err = RunAtmosCommandAndGetStdoutE(t, options, "-c", "echo out; echo err >&2")
+	require.NoError(t, err)
+	assert.Equal(t, "out", out)
+
+	exitCode, err := GetExitCodeForAtmosCommandE(t, options, "-c", "exit 4")
+	require.NoError(t, err)
+	assert.Equal(t, 4, exitCode)
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package atmos
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup does nothing on Windows, where the command is killed on its own.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills the command.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
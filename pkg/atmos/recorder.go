@@ -0,0 +1,73 @@
+package atmos
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/shell"
+)
+
+// CommandRecord describes an atmos command that has finished running, including all of its retries.
+type CommandRecord struct {
+	Binary     string        // The atmos binary that was run
+	Args       []string      // The arguments atmos was run with
+	WorkingDir string        // The directory atmos was run in
+	StartedAt  time.Time     // When the first attempt started
+	Duration   time.Duration // How long the command ran for, across all attempts
+	ExitCode   int           // The exit code of the last attempt
+	Attempts   int           // How many times the command was run, including retries
+	Output     string        // The output of the last attempt
+	Err        error         // The error the command returned, if any
+}
+
+// CommandRecorder receives a record of every atmos command run with Options that set it as their Recorder.
+// Implementations must be safe for concurrent use, since dependencies may be deployed concurrently.
+type CommandRecorder interface {
+	RecordCommand(record CommandRecord)
+}
+
+// recordCommand passes a record of the finished command to the Recorder of the options, if there is one. output and
+// lastErr are those of the last attempt, while err is what the command returned after retrying.
+func recordCommand(options *Options, cmd shell.Command, startedAt time.Time, attempts int, output string, lastErr error, err error) {
+	if options.Recorder == nil {
+		return
+	}
+
+	exitCode, _ := exitCodeForError(lastErr)
+
+	options.Recorder.RecordCommand(CommandRecord{
+		Binary:     cmd.Command,
+		Args:       cmd.Args,
+		WorkingDir: cmd.WorkingDir,
+		StartedAt:  startedAt,
+		Duration:   time.Since(startedAt),
+		ExitCode:   exitCode,
+		Attempts:   attempts,
+		Output:     output,
+		Err:        err,
+	})
+}
+
+// Recorders is a CommandRecorder that passes every record to each of its recorders, e.g. to both a run report and a
+// diagnostics collector. Nil recorders are ignored.
+type Recorders []CommandRecorder
+
+// RecordCommand passes the record to each of the recorders.
+func (r Recorders) RecordCommand(record CommandRecord) {
+	for _, recorder := range r {
+		if recorder != nil {
+			recorder.RecordCommand(record)
+		}
+	}
+}
+
+// FileName makes a test name, which may be a nested test name, usable as the name of a file that records the test,
+// such as a run report or a diagnostics bundle. fallback is returned for an empty name.
+func FileName(name string, fallback string) string {
+	name = strings.NewReplacer("/", "_", " ", "_", string(filepath.Separator), "_").Replace(name)
+	if name == "" {
+		return fallback
+	}
+	return name
+}
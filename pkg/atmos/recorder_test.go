@@ -0,0 +1,69 @@
+package atmos
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRecorder struct {
+	mu      sync.Mutex
+	records []CommandRecord
+}
+
+func (r *fakeRecorder) RecordCommand(record CommandRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records = append(r.records, record)
+}
+
+func TestRunAtmosCommandERecordsCommand(t *testing.T) {
+	recorder := &fakeRecorder{}
+	options := &Options{
+		AtmosBinary:          "sh",
+		AtmosBasePath:        t.TempDir(),
+		Recorder:             recorder,
+		RetryableAtmosErrors: map[string]string{".*transient.*": "transient error"},
+		MaxRetries:           2,
+	}
+
+	_, err := RunAtmosCommandE(t, options, "-c", "echo transient; exit 3")
+	require.Error(t, err)
+
+	require.Len(t, recorder.records, 1)
+	record := recorder.records[0]
+	assert.Equal(t, "sh", record.Binary)
+	assert.Equal(t, []string{"-c", "echo transient; exit 3"}, record.Args)
+	assert.Equal(t, options.AtmosBasePath, record.WorkingDir)
+	assert.Equal(t, 3, record.ExitCode)
+	assert.Equal(t, 3, record.Attempts)
+	assert.Contains(t, record.Output, "transient")
+	assert.Error(t, record.Err)
+
+	_, err = RunAtmosCommandE(t, options, "-c", "echo ok")
+	require.NoError(t, err)
+
+	require.Len(t, recorder.records, 2)
+	assert.Equal(t, DefaultSuccessExitCode, recorder.records[1].ExitCode)
+	assert.Equal(t, 1, recorder.records[1].Attempts)
+	assert.NoError(t, recorder.records[1].Err)
+}
+
+func TestRecordersPassesRecordToEachRecorder(t *testing.T) {
+	first, second := &fakeRecorder{}, &fakeRecorder{}
+	recorders := Recorders{first, nil, second}
+
+	recorders.RecordCommand(CommandRecord{Binary: "atmos", Args: []string{"version"}})
+
+	require.Len(t, first.records, 1)
+	require.Len(t, second.records, 1)
+	assert.Equal(t, []string{"version"}, second.records[0].Args)
+}
+
+func TestFileName(t *testing.T) {
+	assert.Equal(t, "TestRunSuite_deploy_dependencies", FileName("TestRunSuite/deploy dependencies", "report"))
+	assert.Equal(t, "report", FileName("", "report"))
+}
@@ -0,0 +1,91 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Name    string           `xml:"name,attr"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the JUnit XML report to path. Phases and atmos commands are reported as two test suites, with a
+// test case per phase and per command. A command fails when it exits with a non-zero exit code or returns an error.
+func (r *Reporter) WriteJUnit(path string) error {
+	phases := junitTestSuite{Name: r.Suite + "/phases", Timestamp: r.startedAt.Format(time.RFC3339)}
+	total := 0.0
+	for _, phase := range r.Phases() {
+		testCase := junitTestCase{
+			ClassName: phases.Name,
+			Name:      phase.Name,
+			Time:      formatSeconds(phase.DurationSeconds),
+		}
+		switch phase.Status {
+		case StatusFailed:
+			testCase.Failure = &junitMessage{Message: "phase failed", Text: phase.Message}
+			phases.Failures++
+		case StatusSkipped:
+			testCase.Skipped = &junitMessage{Message: phase.Message}
+			phases.Skipped++
+		}
+		phases.TestCases = append(phases.TestCases, testCase)
+		total += phase.DurationSeconds
+	}
+	phases.Tests = len(phases.TestCases)
+	phases.Time = formatSeconds(total)
+
+	commands := junitTestSuite{Name: r.Suite + "/atmos", Timestamp: r.startedAt.Format(time.RFC3339)}
+	total = 0.0
+	for _, command := range r.Commands() {
+		testCase := junitTestCase{
+			ClassName: commands.Name,
+			Name:      strings.Join(command.Args, " "),
+			Time:      formatSeconds(command.DurationSeconds),
+			SystemOut: command.Output,
+		}
+		if command.ExitCode != 0 || command.Error != "" {
+			message := fmt.Sprintf("exit code %d after %d retries", command.ExitCode, command.Retries)
+			testCase.Failure = &junitMessage{Message: message, Text: command.Error}
+			commands.Failures++
+		}
+		commands.TestCases = append(commands.TestCases, testCase)
+		total += command.DurationSeconds
+	}
+	commands.Tests = len(commands.TestCases)
+	commands.Time = formatSeconds(total)
+
+	content, err := xml.MarshalIndent(junitTestSuites{Name: r.Suite, Suites: []junitTestSuite{phases, commands}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append([]byte(xml.Header), content...), 0644)
+}
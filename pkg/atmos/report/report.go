@@ -0,0 +1,191 @@
+// Package report records the phases of a test suite and the atmos commands it runs, and writes them as a JUnit XML
+// report and a JSON report that CI dashboards can consume.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cloudposse/test-helpers/pkg/atmos"
+)
+
+// DefaultMaxOutputSize is the number of bytes of command output kept in the report. Longer output is truncated from
+// the start, since the end of the output is where terraform reports errors.
+const DefaultMaxOutputSize = 4096
+
+// Phase statuses.
+const (
+	StatusPassed  = "passed"
+	StatusSkipped = "skipped"
+	StatusFailed  = "failed"
+)
+
+// Phase is a suite phase that has finished running.
+type Phase struct {
+	Name            string    `json:"name"`
+	Status          string    `json:"status"`
+	StartedAt       time.Time `json:"started_at"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Message         string    `json:"message,omitempty"`
+}
+
+// Command is an atmos command that has finished running.
+type Command struct {
+	Args            []string  `json:"args"`
+	WorkingDir      string    `json:"working_dir"`
+	StartedAt       time.Time `json:"started_at"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	ExitCode        int       `json:"exit_code"`
+	Retries         int       `json:"retries"`
+	Output          string    `json:"output"`
+	OutputTruncated bool      `json:"output_truncated"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// Reporter records phases and atmos commands. It implements atmos.CommandRecorder, so it can be set as the Recorder of
+// atmos.Options. It is safe for concurrent use.
+type Reporter struct {
+	Suite         string // The name of the suite, used for the report file names and the JUnit test suites
+	MaxOutputSize int    // The number of bytes of command output to keep, DefaultMaxOutputSize if zero
+
+	mu        sync.Mutex
+	startedAt time.Time
+	phases    []Phase
+	commands  []Command
+}
+
+// New returns a Reporter for the named suite.
+func New(suite string) *Reporter {
+	return &Reporter{
+		Suite:     suite,
+		startedAt: time.Now(),
+	}
+}
+
+// RecordPhase records a finished phase. status is one of StatusPassed, StatusSkipped or StatusFailed.
+func (r *Reporter) RecordPhase(name string, status string, startedAt time.Time, duration time.Duration, message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.phases = append(r.phases, Phase{
+		Name:            name,
+		Status:          status,
+		StartedAt:       startedAt,
+		DurationSeconds: duration.Seconds(),
+		Message:         message,
+	})
+}
+
+// RecordCommand records a finished atmos command, truncating its output to MaxOutputSize.
+func (r *Reporter) RecordCommand(record atmos.CommandRecord) {
+	maxOutputSize := r.MaxOutputSize
+	if maxOutputSize <= 0 {
+		maxOutputSize = DefaultMaxOutputSize
+	}
+
+	command := Command{
+		Args:            append([]string{record.Binary}, record.Args...),
+		WorkingDir:      record.WorkingDir,
+		StartedAt:       record.StartedAt,
+		DurationSeconds: record.Duration.Seconds(),
+		ExitCode:        record.ExitCode,
+		Retries:         max(record.Attempts-1, 0),
+		Output:          record.Output,
+	}
+	if len(command.Output) > maxOutputSize {
+		// The cut is moved forward to the start of a rune, so that multi-byte characters such as the box drawing of
+		// terraform's diagnostics are not split into invalid UTF-8.
+		start := len(command.Output) - maxOutputSize
+		for start < len(command.Output) && !utf8.RuneStart(command.Output[start]) {
+			start++
+		}
+		command.Output = command.Output[start:]
+		command.OutputTruncated = true
+	}
+	if record.Err != nil {
+		command.Error = record.Err.Error()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.commands = append(r.commands, command)
+}
+
+// Phases returns the phases recorded so far.
+func (r *Reporter) Phases() []Phase {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Phase(nil), r.phases...)
+}
+
+// Commands returns the atmos commands recorded so far.
+func (r *Reporter) Commands() []Command {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Command(nil), r.commands...)
+}
+
+// Write writes the JUnit XML report to <dir>/<suite>.junit.xml and the JSON report to <dir>/<suite>.json, creating dir
+// if needed. It returns the paths of the reports.
+func (r *Reporter) Write(dir string) (junitPath string, jsonPath string, err error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", err
+	}
+
+	name := atmos.FileName(r.Suite, "report")
+	junitPath = filepath.Join(dir, name+".junit.xml")
+	jsonPath = filepath.Join(dir, name+".json")
+
+	if err := r.WriteJUnit(junitPath); err != nil {
+		return "", "", err
+	}
+	if err := r.WriteJSON(jsonPath); err != nil {
+		return "", "", err
+	}
+	return junitPath, jsonPath, nil
+}
+
+type jsonReport struct {
+	Suite           string    `json:"suite"`
+	StartedAt       time.Time `json:"started_at"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Phases          []Phase   `json:"phases"`
+	Commands        []Command `json:"commands"`
+}
+
+// WriteJSON writes the JSON report to path.
+func (r *Reporter) WriteJSON(path string) error {
+	report := jsonReport{
+		Suite:           r.Suite,
+		StartedAt:       r.startedAt,
+		DurationSeconds: time.Since(r.startedAt).Seconds(),
+		Phases:          r.Phases(),
+		Commands:        r.Commands(),
+	}
+	if report.Phases == nil {
+		report.Phases = []Phase{}
+	}
+	if report.Commands == nil {
+		report.Commands = []Command{}
+	}
+
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+func formatSeconds(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
+
+var _ atmos.CommandRecorder = (*Reporter)(nil)
@@ -0,0 +1,100 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cloudposse/test-helpers/pkg/atmos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordCommandTruncatesOutput(t *testing.T) {
+	r := New("TestSuite")
+	r.MaxOutputSize = 5
+
+	r.RecordCommand(atmos.CommandRecord{
+		Binary:   "atmos",
+		Args:     []string{"terraform", "apply", "vpc"},
+		Duration: 2 * time.Second,
+		ExitCode: 1,
+		Attempts: 3,
+		Output:   "Error: boom",
+		Err:      errors.New("exit status 1"),
+	})
+
+	commands := r.Commands()
+	require.Len(t, commands, 1)
+	assert.Equal(t, []string{"atmos", "terraform", "apply", "vpc"}, commands[0].Args)
+	assert.Equal(t, " boom", commands[0].Output)
+	assert.True(t, commands[0].OutputTruncated)
+	assert.Equal(t, 2, commands[0].Retries)
+	assert.Equal(t, 2.0, commands[0].DurationSeconds)
+	assert.Equal(t, "exit status 1", commands[0].Error)
+}
+
+func TestRecordCommandTruncatesOutputAtRuneStart(t *testing.T) {
+	r := New("TestSuite")
+	r.MaxOutputSize = 5
+
+	// The last 5 bytes start inside ╷, which takes 3 bytes.
+	r.RecordCommand(atmos.CommandRecord{Binary: "atmos", Output: "Error: ╷│"})
+
+	commands := r.Commands()
+	require.Len(t, commands, 1)
+	assert.Equal(t, "│", commands[0].Output)
+	assert.True(t, utf8.ValidString(commands[0].Output))
+	assert.True(t, commands[0].OutputTruncated)
+}
+
+func TestWrite(t *testing.T) {
+	r := New("TestRunSuite/nested")
+	now := time.Now()
+	r.RecordPhase("setup/bootstrap temp dir", StatusPassed, now, time.Second, "")
+	r.RecordPhase("setup/validate stacks", StatusSkipped, now, 0, "")
+	r.RecordPhase("deploy dependencies/default-test/vpc", StatusFailed, now, 3*time.Second, "apply failed")
+	r.RecordCommand(atmos.CommandRecord{Binary: "atmos", Args: []string{"vendor", "pull"}, Attempts: 1, Output: "ok"})
+	r.RecordCommand(atmos.CommandRecord{Binary: "atmos", Args: []string{"terraform", "apply", "vpc"}, ExitCode: 1, Attempts: 1})
+
+	dir := filepath.Join(t.TempDir(), "reports")
+	junitPath, jsonPath, err := r.Write(dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "TestRunSuite_nested.junit.xml"), junitPath)
+	assert.Equal(t, filepath.Join(dir, "TestRunSuite_nested.json"), jsonPath)
+
+	content, err := os.ReadFile(junitPath)
+	require.NoError(t, err)
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(content, &suites))
+	require.Len(t, suites.Suites, 2)
+
+	phases := suites.Suites[0]
+	assert.Equal(t, "TestRunSuite/nested/phases", phases.Name)
+	assert.Equal(t, 3, phases.Tests)
+	assert.Equal(t, 1, phases.Failures)
+	assert.Equal(t, 1, phases.Skipped)
+	assert.Equal(t, "4.000", phases.Time)
+	assert.Equal(t, "apply failed", phases.TestCases[2].Failure.Text)
+
+	commands := suites.Suites[1]
+	assert.Equal(t, 2, commands.Tests)
+	assert.Equal(t, 1, commands.Failures)
+	assert.Equal(t, "atmos terraform apply vpc", commands.TestCases[1].Name)
+	assert.True(t, strings.HasPrefix(commands.TestCases[1].Failure.Message, "exit code 1"))
+
+	content, err = os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	var report jsonReport
+	require.NoError(t, json.Unmarshal(content, &report))
+	assert.Equal(t, "TestRunSuite/nested", report.Suite)
+	assert.Len(t, report.Phases, 3)
+	assert.Len(t, report.Commands, 2)
+	assert.Equal(t, "ok", report.Commands[0].Output)
+}
@@ -0,0 +1,169 @@
+package atmos
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// RetryableError is an error that an atmos command is retried on.
+type RetryableError struct {
+	Pattern        string        // A regexp to match against the output and the error of the command
+	Message        string        // What to display to a user if the error is matched
+	MaxRetries     int           // Retries this error up to this many times instead of counting it against the MaxRetries of the policy, if set
+	InitialBackoff time.Duration // Overrides the InitialBackoff of the policy for this error, if set
+}
+
+// RetryPolicy decides whether a failed atmos command is run again and how long to wait before doing so. The wait grows
+// exponentially with every retry, and jitter spreads the retries of tests that run in parallel, so that they don't all
+// hit e.g. the provider registry at the same time.
+type RetryPolicy struct {
+	Errors         []RetryableError // The errors to retry on. The first one that matches applies.
+	MaxRetries     int              // The maximum number of retries of a command, shared by the errors that don't override it
+	InitialBackoff time.Duration    // How long to wait before the first retry
+	MaxBackoff     time.Duration    // The maximum time to wait between retries, no limit if zero
+	Multiplier     float64          // What the wait is multiplied by with every retry, 2 if zero
+	Jitter         float64          // The fraction of the wait, between 0 and 1, by which it is randomly shortened or lengthened
+	MaxElapsedTime time.Duration    // No retry is started once this much time has passed since the first attempt, no limit if zero
+}
+
+// eventualConsistencyMaxRetries is how often DefaultRetryPolicy retries provider eventual consistency errors.
+const eventualConsistencyMaxRetries = 10
+
+// DefaultRetryPolicy returns a policy that retries DefaultRetryableAtmosErrors with exponential backoff and jitter.
+// Provider eventual consistency errors are retried more often than the others, since they take a while to resolve.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: 5 * time.Second,
+		MaxBackoff:     time.Minute,
+		Multiplier:     2,
+		Jitter:         0.5,
+		MaxElapsedTime: 20 * time.Minute,
+		Errors:         append([]RetryableError(nil), defaultRetryableErrors...),
+	}
+}
+
+// retryableErrorMessages converts retryable errors to a map of their patterns to their messages, like
+// Options.RetryableAtmosErrors.
+func retryableErrorMessages(errors []RetryableError) map[string]string {
+	messages := make(map[string]string, len(errors))
+	for _, retryable := range errors {
+		messages[retryable.Pattern] = retryable.Message
+	}
+	return messages
+}
+
+// retryableErrors converts a map of retryable errors, like Options.RetryableAtmosErrors, sorted by pattern so that the
+// order they are matched in is stable.
+func retryableErrors(errors map[string]string) []RetryableError {
+	patterns := make([]string, 0, len(errors))
+	for pattern := range errors {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	retryable := make([]RetryableError, 0, len(patterns))
+	for _, pattern := range patterns {
+		retryable = append(retryable, RetryableError{Pattern: pattern, Message: errors[pattern]})
+	}
+	return retryable
+}
+
+// retryPolicyFor returns the RetryPolicy of the options or, if it is not set, a policy that retries
+// RetryableAtmosErrors up to MaxRetries times in total, waiting TimeBetweenRetries in between.
+func retryPolicyFor(options *Options) *RetryPolicy {
+	if options.RetryPolicy != nil {
+		return options.RetryPolicy
+	}
+	return &RetryPolicy{
+		Errors:         retryableErrors(options.RetryableAtmosErrors),
+		MaxRetries:     options.MaxRetries,
+		InitialBackoff: options.TimeBetweenRetries,
+		Multiplier:     1,
+	}
+}
+
+// Clone makes a copy of the policy that does not share its errors.
+func (p *RetryPolicy) Clone() *RetryPolicy {
+	if p == nil {
+		return nil
+	}
+	clone := *p
+	clone.Errors = append([]RetryableError(nil), p.Errors...)
+	return &clone
+}
+
+// match returns the first of the retryable errors that matches the output of the command or its error.
+func (p *RetryPolicy) match(output string, err error) (*RetryableError, error) {
+	for i, retryable := range p.Errors {
+		re, compileErr := regexp.Compile(retryable.Pattern)
+		if compileErr != nil {
+			return nil, fmt.Errorf("cannot compile regex for retryable error %q: %w", retryable.Pattern, compileErr)
+		}
+		if re.MatchString(output) || re.MatchString(err.Error()) {
+			return &p.Errors[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// retryBudget returns the key under which the retries of the error are counted. Errors that override MaxRetries have
+// a budget of their own, the others share the budget of the policy under the empty key.
+func (p *RetryPolicy) retryBudget(retryable *RetryableError) string {
+	if retryable.MaxRetries > 0 {
+		return retryable.Pattern
+	}
+	return ""
+}
+
+// backoff returns how long to wait before retrying the error for the retry+1th time, and false if the error may not be
+// retried again. retry counts the retries made so far from the budget of the error.
+func (p *RetryPolicy) backoff(retryable *RetryableError, retry int, elapsed time.Duration) (time.Duration, bool) {
+	maxRetries := p.MaxRetries
+	if retryable.MaxRetries > 0 {
+		maxRetries = retryable.MaxRetries
+	}
+	if retry >= maxRetries {
+		return 0, false
+	}
+
+	initial := p.InitialBackoff
+	if retryable.InitialBackoff > 0 {
+		initial = retryable.InitialBackoff
+	}
+	multiplier := p.Multiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+
+	wait := float64(initial) * math.Pow(multiplier, float64(retry))
+	if p.MaxBackoff > 0 && wait > float64(p.MaxBackoff) {
+		wait = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		wait += wait * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	if p.MaxElapsedTime > 0 && elapsed+time.Duration(wait) > p.MaxElapsedTime {
+		return 0, false
+	}
+	return time.Duration(wait), true
+}
+
+// sleepContext waits for d to pass, returning the context error if ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,164 @@
+package atmos
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &RetryPolicy{MaxRetries: 5, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	retryable := &RetryableError{Pattern: ".*"}
+
+	var waits []time.Duration
+	for retry := 0; ; retry++ {
+		wait, ok := policy.backoff(retryable, retry, 0)
+		if !ok {
+			break
+		}
+		waits = append(waits, wait)
+	}
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}, waits)
+}
+
+func TestRetryPolicyBackoffJitter(t *testing.T) {
+	policy := &RetryPolicy{MaxRetries: 1, InitialBackoff: 10 * time.Second, Jitter: 0.5}
+	retryable := &RetryableError{Pattern: ".*"}
+
+	for i := 0; i < 100; i++ {
+		wait, ok := policy.backoff(retryable, 0, 0)
+		require.True(t, ok)
+		assert.GreaterOrEqual(t, wait, 5*time.Second)
+		assert.LessOrEqual(t, wait, 15*time.Second)
+	}
+}
+
+func TestRetryPolicyBackoffOverridesAndLimits(t *testing.T) {
+	policy := &RetryPolicy{MaxRetries: 1, InitialBackoff: time.Second, MaxElapsedTime: time.Minute}
+
+	override := &RetryableError{Pattern: ".*", MaxRetries: 3, InitialBackoff: 10 * time.Second}
+	wait, ok := policy.backoff(override, 2, 0)
+	require.True(t, ok)
+	assert.Equal(t, 40*time.Second, wait)
+	_, ok = policy.backoff(override, 3, 0)
+	assert.False(t, ok)
+
+	_, ok = policy.backoff(&RetryableError{Pattern: ".*"}, 1, 0)
+	assert.False(t, ok)
+
+	// A retry that would start after MaxElapsedTime is not made.
+	_, ok = policy.backoff(override, 0, 55*time.Second)
+	assert.False(t, ok)
+}
+
+func TestRunAtmosCommandEWithRetryPolicy(t *testing.T) {
+	executor := NewFakeExecutor(
+		FakeResponse{Args: []string{"terraform", "init"}, Stderr: "Error: registry service is unreachable", ExitCode: 1},
+		FakeResponse{Args: []string{"terraform", "apply"}, Stderr: "Error: Provider produced inconsistent result after apply", ExitCode: 1},
+		FakeResponse{Args: []string{"terraform", "plan"}, Stderr: "Error: Invalid reference", ExitCode: 1},
+	)
+	options := &Options{
+		Executor: executor,
+		RetryPolicy: &RetryPolicy{
+			MaxRetries:     2,
+			InitialBackoff: time.Millisecond,
+			Errors: []RetryableError{
+				{Pattern: ".*registry service is unreachable.*", Message: "registry"},
+				{Pattern: ".*inconsistent result after apply.*", Message: "eventual consistency", MaxRetries: 4},
+			},
+		},
+	}
+
+	result, err := RunAtmosCommandResultE(t, options, "terraform", "init", "vpc")
+	var exhausted RetriesExhausted
+	require.True(t, errors.As(err, &exhausted))
+	assert.Equal(t, 2, exhausted.Retries)
+	assert.Equal(t, 3, result.Attempts)
+	var failed *CommandFailed
+	assert.True(t, errors.As(err, &failed))
+
+	result, err = RunAtmosCommandResultE(t, options, "terraform", "apply", "vpc")
+	require.Error(t, err)
+	assert.Equal(t, 5, result.Attempts)
+
+	result, err = RunAtmosCommandResultE(t, options, "terraform", "plan", "vpc")
+	require.True(t, errors.As(err, &failed))
+	assert.Equal(t, 1, result.Attempts)
+}
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	require.Len(t, policy.Errors, len(DefaultRetryableAtmosErrors))
+
+	retryable, err := policy.match("", errors.New("Error: Provider produced inconsistent result after apply"))
+	require.NoError(t, err)
+	require.NotNil(t, retryable)
+	assert.Equal(t, 10, retryable.MaxRetries)
+
+	retryable, err = policy.match("", errors.New("Error: registry service is unreachable"))
+	require.NoError(t, err)
+	require.NotNil(t, retryable)
+	assert.Zero(t, retryable.MaxRetries)
+	assert.Equal(t, 3, policy.MaxRetries)
+}
+
+func TestWithDefaultRetryableErrorsKeepsRetrySettings(t *testing.T) {
+	options := WithDefaultRetryableErrors(t, &Options{})
+	assert.Nil(t, options.RetryPolicy)
+	assert.Zero(t, options.MaxRetries)
+
+	policy := retryPolicyFor(options)
+	_, ok := policy.backoff(&RetryableError{Pattern: ".*"}, 0, 0)
+	assert.False(t, ok, "MaxRetries 0 means no retries")
+}
+
+func TestWithDefaultRetryableErrorsSetsDefaultRetryPolicy(t *testing.T) {
+	options := WithDefaultRetryableErrors(t, &Options{MaxRetries: -1})
+	require.NotNil(t, options.RetryPolicy)
+	assert.Equal(t, DefaultRetryPolicy(), options.RetryPolicy)
+	assert.Equal(t, options.RetryPolicy, retryPolicyFor(options))
+
+	options = WithDefaultRetryableErrors(t, &Options{MaxRetries: -1, RetryableAtmosErrors: map[string]string{".*throttled.*": "Throttled."}})
+	assert.Equal(t, []RetryableError{{Pattern: ".*throttled.*", Message: "Throttled."}}, options.RetryPolicy.Errors)
+	assert.Equal(t, 0.5, options.RetryPolicy.Jitter)
+
+	policy := &RetryPolicy{MaxRetries: 1}
+	options = WithDefaultRetryableErrors(t, &Options{MaxRetries: -1, RetryPolicy: policy})
+	assert.Equal(t, policy, options.RetryPolicy, "a policy of the caller is kept")
+}
+
+func TestRunAtmosCommandESharesMaxRetriesAcrossErrors(t *testing.T) {
+	executor := NewFakeExecutor(
+		FakeResponse{Args: []string{"terraform", "init"}, Stderr: "Error: registry service is unreachable", ExitCode: 1},
+		FakeResponse{Args: []string{"terraform", "init"}, Stderr: "Error: unable to verify checksum", ExitCode: 1},
+		FakeResponse{Args: []string{"terraform", "init"}, Stderr: "Error: registry service is unreachable", ExitCode: 1},
+		FakeResponse{Args: []string{"terraform", "init"}},
+	)
+	options := &Options{
+		Executor:           executor,
+		MaxRetries:         2,
+		TimeBetweenRetries: time.Millisecond,
+		RetryableAtmosErrors: map[string]string{
+			".*registry service is unreachable.*": "registry",
+			".*unable to verify checksum.*":       "checksum",
+		},
+	}
+
+	result, err := RunAtmosCommandResultE(t, options, "terraform", "init", "vpc")
+	var exhausted RetriesExhausted
+	require.True(t, errors.As(err, &exhausted))
+	assert.Equal(t, 2, exhausted.Retries)
+	assert.Equal(t, 3, result.Attempts)
+}
+
+func TestOptionsCloneDeepClonesRetryPolicy(t *testing.T) {
+	original := Options{RetryPolicy: DefaultRetryPolicy()}
+	copied, err := original.Clone()
+	require.NoError(t, err)
+
+	copied.RetryPolicy.Errors[0].MaxRetries = 42
+	assert.NotEqual(t, 42, original.RetryPolicy.Errors[0].MaxRetries)
+}
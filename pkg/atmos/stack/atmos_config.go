@@ -0,0 +1,73 @@
+package stack
+
+import (
+	"path/filepath"
+)
+
+// AtmosConfig is the atmos.yaml of the atmos project that the generated manifests are written to.
+type AtmosConfig struct {
+	ComponentsBasePath      string   // The directory of the terraform components, relative to the project
+	StacksBasePath          string   // The directory of the stack manifests, relative to the project
+	IncludedPaths           []string // The globs of the manifests that define stacks, relative to StacksBasePath
+	ExcludedPaths           []string // The globs of the manifests that are only imported, relative to StacksBasePath
+	NamePattern             string   // The pattern stack names are built from, out of the vars of the stack
+	WorkflowsBasePath       string   // The directory of the workflow files, relative to the project
+	AutoGenerateBackendFile bool     // Generates backend.tf.json from the backend section of each component
+}
+
+// NewAtmosConfig returns the atmos.yaml of a project laid out like the temp dir of a test suite: components under
+// components/terraform, and stacks under stacks/orgs named {tenant}-{environment}-{stage}.
+func NewAtmosConfig() *AtmosConfig {
+	return &AtmosConfig{
+		ComponentsBasePath:      "components/terraform",
+		StacksBasePath:          "stacks",
+		IncludedPaths:           []string{"orgs/**/*"},
+		ExcludedPaths:           []string{"**/_defaults.yaml"},
+		NamePattern:             "{tenant}-{environment}-{stage}",
+		WorkflowsBasePath:       "stacks/workflows",
+		AutoGenerateBackendFile: true,
+	}
+}
+
+// YAML returns the atmos.yaml.
+func (c *AtmosConfig) YAML() ([]byte, error) {
+	return marshal(map[string]interface{}{
+		"base_path": ".",
+		"components": map[string]interface{}{
+			"terraform": map[string]interface{}{
+				"base_path":                  c.ComponentsBasePath,
+				"apply_auto_approve":         true,
+				"deploy_run_init":            true,
+				"init_run_reconfigure":       true,
+				"auto_generate_backend_file": c.AutoGenerateBackendFile,
+			},
+		},
+		"stacks": map[string]interface{}{
+			"base_path":      c.StacksBasePath,
+			"included_paths": c.IncludedPaths,
+			"excluded_paths": c.ExcludedPaths,
+			"name_pattern":   c.NamePattern,
+		},
+		"workflows": map[string]interface{}{
+			"base_path": c.WorkflowsBasePath,
+		},
+		"logs": map[string]interface{}{
+			"file":  "/dev/stderr",
+			"level": "Info",
+		},
+	})
+}
+
+// Write writes the atmos.yaml to <dir>/atmos.yaml, creating dir if needed, and returns its path.
+func (c *AtmosConfig) Write(dir string) (string, error) {
+	content, err := c.YAML()
+	if err != nil {
+		return "", err
+	}
+	return writeFile(filepath.Join(dir, "atmos.yaml"), content)
+}
+
+// StacksDir returns the directory of the stack manifests of the project in dir, to write manifests to.
+func (c *AtmosConfig) StacksDir(dir string) string {
+	return filepath.Join(dir, filepath.FromSlash(c.StacksBasePath))
+}
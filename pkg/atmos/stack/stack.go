@@ -0,0 +1,250 @@
+// Package stack builds atmos stack manifests and atmos.yaml in code, so that a test can generate the stacks it deploys
+// instead of maintaining a YAML fixture for every variation of a component test.
+//
+//	stack.New("orgs/test/main").
+//		Import("catalog/defaults").
+//		Vars(map[string]interface{}{"tenant": "test", "environment": "use2", "stage": "sandbox"}).
+//		Component("target", map[string]interface{}{"enabled": true}).
+//		Write(filepath.Join(tempDir, "stacks"))
+package stack
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"dario.cat/mergo"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrorComponentNameRequired = fmt.Errorf("you must set the name of a component to add it to a manifest")
+	ErrorManifestNameRequired  = fmt.Errorf("you must set the name of a manifest to write it")
+)
+
+// Manifest is an atmos stack manifest. Its methods return the manifest, so that calls can be chained. An invalid call,
+// such as adding a component without a name, is reported by YAML and Write.
+type Manifest struct {
+	name       string
+	imports    []string
+	vars       map[string]interface{}
+	terraform  map[string]interface{}
+	components map[string]map[string]interface{}
+	err        error
+}
+
+// New returns an empty manifest named after its path relative to the stacks directory, without the .yaml extension,
+// e.g. orgs/test/main.
+func New(name string) *Manifest {
+	return &Manifest{
+		name:       strings.TrimSuffix(name, ".yaml"),
+		vars:       map[string]interface{}{},
+		terraform:  map[string]interface{}{},
+		components: map[string]map[string]interface{}{},
+	}
+}
+
+// Name returns the path of the manifest relative to the stacks directory, without the .yaml extension.
+func (m *Manifest) Name() string {
+	return m.name
+}
+
+// Import adds manifests to import, by their path relative to the stacks directory.
+func (m *Manifest) Import(paths ...string) *Manifest {
+	m.imports = append(m.imports, paths...)
+	return m
+}
+
+// Vars merges a copy of vars into the global vars of the manifest, which every component in the stack inherits.
+func (m *Manifest) Vars(vars map[string]interface{}) *Manifest {
+	m.merge(m.vars, vars)
+	return m
+}
+
+// Terraform merges a copy of section into the terraform section of the manifest, e.g. to configure the backend of every
+// component in the stack.
+func (m *Manifest) Terraform(section map[string]interface{}) *Manifest {
+	m.merge(m.terraform, section)
+	return m
+}
+
+// LocalBackend configures every component in the stack to keep its state in a local backend under stateDir, at
+// <stateDir>/<component>/terraform.tfstate.
+func (m *Manifest) LocalBackend(stateDir string) *Manifest {
+	return m.Terraform(map[string]interface{}{
+		"backend_type": "local",
+		"backend": map[string]interface{}{
+			"local": map[string]interface{}{
+				"path":          filepath.ToSlash(filepath.Join(stateDir, "{{ .component }}", "terraform.tfstate")),
+				"workspace_dir": filepath.ToSlash(filepath.Join(stateDir, "{{ .component }}")) + "/",
+			},
+		},
+	})
+}
+
+// Component adds a terraform component with the given vars. Adding a component again merges the vars into those it
+// was added with before.
+func (m *Manifest) Component(name string, vars map[string]interface{}) *Manifest {
+	return m.ComponentSection(name, componentSection(nil, vars))
+}
+
+// ComponentFrom adds an instance of the terraform component in the directory terraformComponent, named name, e.g. to
+// deploy the component under test more than once in the same stack.
+func (m *Manifest) ComponentFrom(name string, terraformComponent string, vars map[string]interface{}) *Manifest {
+	return m.ComponentSection(name, componentSection(map[string]interface{}{"component": terraformComponent}, vars))
+}
+
+// componentSection returns the section of a component with the given metadata and vars, leaving out the empty ones.
+func componentSection(metadata map[string]interface{}, vars map[string]interface{}) map[string]interface{} {
+	section := map[string]interface{}{}
+	if metadata != nil {
+		section["metadata"] = metadata
+	}
+	if vars != nil {
+		section["vars"] = vars
+	}
+	return section
+}
+
+// ComponentSection merges a copy of section into the configuration of a terraform component, e.g. to set its settings or
+// metadata.
+func (m *Manifest) ComponentSection(name string, section map[string]interface{}) *Manifest {
+	if name == "" {
+		m.fail(ErrorComponentNameRequired)
+		return m
+	}
+
+	component, ok := m.components[name]
+	if !ok {
+		component = map[string]interface{}{}
+		m.components[name] = component
+	}
+	m.merge(component, section)
+	return m
+}
+
+type manifestYAML struct {
+	Import     []string               `yaml:"import,omitempty"`
+	Vars       map[string]interface{} `yaml:"vars,omitempty"`
+	Terraform  map[string]interface{} `yaml:"terraform,omitempty"`
+	Components *componentsYAML        `yaml:"components,omitempty"`
+}
+
+type componentsYAML struct {
+	Terraform map[string]map[string]interface{} `yaml:"terraform"`
+}
+
+// YAML returns the manifest as YAML.
+func (m *Manifest) YAML() ([]byte, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.name == "" {
+		return nil, ErrorManifestNameRequired
+	}
+
+	manifest := manifestYAML{
+		Import:    m.imports,
+		Vars:      m.vars,
+		Terraform: m.terraform,
+	}
+	if len(m.components) > 0 {
+		manifest.Components = &componentsYAML{Terraform: m.components}
+	}
+	return marshal(manifest)
+}
+
+// Write writes the manifest to <stacksDir>/<name>.yaml, creating its directories if needed, and returns its path.
+func (m *Manifest) Write(stacksDir string) (string, error) {
+	content, err := m.YAML()
+	if err != nil {
+		return "", err
+	}
+	return writeFile(filepath.Join(stacksDir, filepath.FromSlash(m.name)+".yaml"), content)
+}
+
+// merge merges a deep copy of src into dst, since mergo stores the maps and slices of src in dst as they are, and
+// later calls would then modify the maps of the caller.
+func (m *Manifest) merge(dst map[string]interface{}, src map[string]interface{}) {
+	if src == nil {
+		return
+	}
+	src = deepCopy(src).(map[string]interface{})
+	if err := mergo.Merge(&dst, src, mergo.WithOverride); err != nil {
+		m.fail(fmt.Errorf("merging into manifest %s: %w", m.name, err))
+	}
+}
+
+// deepCopy returns a copy of value in which every map and slice is copied as well.
+func deepCopy(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return deepCopyValue(reflect.ValueOf(value)).Interface()
+}
+
+func deepCopyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopyValue(v.Elem()))
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopyValue(iter.Value()))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopyValue(v.Index(i)))
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// fail records the first error of the calls made on the manifest.
+func (m *Manifest) fail(err error) {
+	if m.err == nil {
+		m.err = err
+	}
+}
+
+func marshal(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeFile(path string, content []byte) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
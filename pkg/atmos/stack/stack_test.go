@@ -0,0 +1,148 @@
+package stack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestManifestYAML(t *testing.T) {
+	m := New("orgs/test/main").
+		Import("catalog/defaults").
+		Vars(map[string]interface{}{"tenant": "test", "environment": "use2", "stage": "sandbox"}).
+		Component("target", map[string]interface{}{"enabled": true}).
+		ComponentFrom("target2", "target", map[string]interface{}{"revision": 2})
+
+	content, err := m.YAML()
+	require.NoError(t, err)
+	assert.Equal(t, `import:
+  - catalog/defaults
+vars:
+  environment: use2
+  stage: sandbox
+  tenant: test
+components:
+  terraform:
+    target:
+      vars:
+        enabled: true
+    target2:
+      metadata:
+        component: target
+      vars:
+        revision: 2
+`, string(content))
+}
+
+func TestManifestMergesSections(t *testing.T) {
+	m := New("orgs/test/main.yaml").
+		Vars(map[string]interface{}{"tenant": "test", "label_order": []string{"namespace"}}).
+		Vars(map[string]interface{}{"stage": "sandbox"}).
+		Component("target", map[string]interface{}{"enabled": true, "tags": map[string]interface{}{"a": "1"}}).
+		Component("target", map[string]interface{}{"enabled": false, "tags": map[string]interface{}{"b": "2"}}).
+		ComponentSection("target", map[string]interface{}{"settings": map[string]interface{}{"depends_on": map[string]interface{}{"1": map[string]interface{}{"component": "vpc"}}}}).
+		Component("dns", nil)
+
+	content, err := m.YAML()
+	require.NoError(t, err)
+
+	var manifest map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(content, &manifest))
+	assert.Equal(t, map[string]interface{}{"tenant": "test", "stage": "sandbox", "label_order": []interface{}{"namespace"}}, manifest["vars"])
+
+	components := manifest["components"].(map[string]interface{})["terraform"].(map[string]interface{})
+	target := components["target"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"enabled": false, "tags": map[string]interface{}{"a": "1", "b": "2"}}, target["vars"])
+	assert.Contains(t, target, "settings")
+	assert.Equal(t, map[string]interface{}{}, components["dns"])
+	assert.Equal(t, "orgs/test/main", m.Name())
+}
+
+func TestManifestDoesNotModifyCallerMaps(t *testing.T) {
+	vars := map[string]interface{}{"tags": map[string]interface{}{"a": "1"}, "label_order": []string{"namespace"}}
+	section := map[string]interface{}{"settings": map[string]interface{}{"depends_on": map[string]interface{}{}}}
+	backend := map[string]interface{}{"backend": map[string]interface{}{"s3": map[string]interface{}{"bucket": "state"}}}
+
+	m := New("orgs/test/main").
+		Vars(vars).
+		Vars(map[string]interface{}{"tags": map[string]interface{}{"b": "2"}}).
+		Terraform(backend).
+		LocalBackend("../../../state").
+		Component("target", vars).
+		Component("target", map[string]interface{}{"tags": map[string]interface{}{"c": "3"}}).
+		ComponentFrom("target2", "target", vars).
+		ComponentSection("target", section).
+		ComponentSection("target", map[string]interface{}{"settings": map[string]interface{}{"depends_on": map[string]interface{}{"1": "vpc"}}})
+	_, err := m.YAML()
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{"tags": map[string]interface{}{"a": "1"}, "label_order": []string{"namespace"}}, vars)
+	assert.Equal(t, map[string]interface{}{"settings": map[string]interface{}{"depends_on": map[string]interface{}{}}}, section)
+	assert.Equal(t, map[string]interface{}{"backend": map[string]interface{}{"s3": map[string]interface{}{"bucket": "state"}}}, backend)
+
+	vars["tags"].(map[string]interface{})["d"] = "4"
+	assert.NotContains(t, m.vars["tags"], "d", "the manifest does not keep the maps of the caller")
+}
+
+func TestManifestWrite(t *testing.T) {
+	stacksDir := filepath.Join(t.TempDir(), "stacks")
+
+	path, err := New("orgs/test/main").
+		LocalBackend("../../../state").
+		Component("target", map[string]interface{}{"enabled": true}).
+		Write(stacksDir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(stacksDir, "orgs", "test", "main.yaml"), path)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var manifest map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(content, &manifest))
+	terraform := manifest["terraform"].(map[string]interface{})
+	assert.Equal(t, "local", terraform["backend_type"])
+	assert.Equal(t, map[string]interface{}{
+		"local": map[string]interface{}{
+			"path":          "../../../state/{{ .component }}/terraform.tfstate",
+			"workspace_dir": "../../../state/{{ .component }}/",
+		},
+	}, terraform["backend"])
+}
+
+func TestManifestReportsInvalidCalls(t *testing.T) {
+	_, err := New("orgs/test/main").Component("", nil).Write(t.TempDir())
+	assert.ErrorIs(t, err, ErrorComponentNameRequired)
+
+	_, err = New("").YAML()
+	assert.ErrorIs(t, err, ErrorManifestNameRequired)
+}
+
+func TestAtmosConfigWrite(t *testing.T) {
+	dir := t.TempDir()
+	config := NewAtmosConfig()
+
+	path, err := config.Write(dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "atmos.yaml"), path)
+	assert.Equal(t, filepath.Join(dir, "stacks"), config.StacksDir(dir))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var atmosConfig map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(content, &atmosConfig))
+	assert.Equal(t, ".", atmosConfig["base_path"])
+	assert.Equal(t, map[string]interface{}{
+		"base_path":      "stacks",
+		"included_paths": []interface{}{"orgs/**/*"},
+		"excluded_paths": []interface{}{"**/_defaults.yaml"},
+		"name_pattern":   "{tenant}-{environment}-{stage}",
+	}, atmosConfig["stacks"])
+	terraform := atmosConfig["components"].(map[string]interface{})["terraform"].(map[string]interface{})
+	assert.Equal(t, "components/terraform", terraform["base_path"])
+	assert.Equal(t, true, terraform["auto_generate_backend_file"])
+}
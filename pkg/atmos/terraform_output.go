@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -310,27 +311,36 @@ func OutputStructE(t testing.TestingT, options *Options, key string, v interface
 	return json.Unmarshal([]byte(out), &v)
 }
 
-// cleanOutput removes lines containing "INFO" and non-printable ASCII characters from the output.
+// escapeSequence matches the terminal escape sequences atmos uses to color its output.
+var escapeSequence = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// cleanOutput returns the JSON document in the stdout of atmos. Lines written before the document, such as "Switched to
+// workspace" or debug lines from GHA, and lines written after it are skipped, and terminal escape sequences are removed.
+// The stdout is returned as is, apart from escape sequences and surrounding whitespace, if it contains no JSON document.
 func cleanOutput(out string) string {
-	var result []rune
-	for _, line := range strings.Split(out, "\n") {
-		if strings.Contains(line, "INFO") {
-			continue
-		}
-		if strings.Contains(line, "Switched to workspace") {
-			continue
-		}
-		// Skip debug lines from GHA
-		if strings.Contains(line, "::debug::") {
-			continue
-		}
-		for _, r := range line {
-			if r >= 32 && r < 127 { // Keep printable ASCII characters only
-				result = append(result, r)
+	out = escapeSequence.ReplaceAllString(out, "")
+
+	for start := 0; start < len(out); {
+		// A document has to end its line, so that log lines that start like JSON, e.g. with a date, are skipped.
+		var doc json.RawMessage
+		decoder := json.NewDecoder(strings.NewReader(out[start:]))
+		if err := decoder.Decode(&doc); err == nil {
+			rest := out[start+int(decoder.InputOffset()):]
+			if end := strings.IndexByte(rest, '\n'); end >= 0 {
+				rest = rest[:end]
 			}
+			if strings.TrimSpace(rest) == "" {
+				return string(doc)
+			}
+		}
+
+		end := strings.IndexByte(out[start:], '\n')
+		if end < 0 {
+			break
 		}
+		start += end + 1
 	}
-	return string(result)
+	return strings.TrimSpace(out)
 }
 
 // OutputForKeysE calls terraform output for the given key list and returns values as a map.
@@ -27,6 +27,47 @@ func PlanE(t testing.TestingT, options *Options) (string, error) {
 	return RunAtmosCommandE(t, options, FormatArgs(options, "terraform", "plan", "-input=false", "-lock=false")...)
 }
 
+// PlanAndShow runs terraform plan with the given options, writing the plan to PlanFilePath, and then runs terraform
+// show on the plan file, returning the json representation of the plan. This will fail the test if there is an error in
+// either command.
+func PlanAndShow(t testing.TestingT, options *Options) string {
+	out, err := PlanAndShowE(t, options)
+	require.NoError(t, err)
+	return out
+}
+
+// PlanAndShowE runs terraform plan with the given options, writing the plan to PlanFilePath, and then runs terraform
+// show on the plan file, returning the json representation of the plan.
+func PlanAndShowE(t testing.TestingT, options *Options) (string, error) {
+	if options.PlanFilePath == "" {
+		return "", ErrorPlanFilePathRequired
+	}
+
+	if _, err := PlanE(t, options); err != nil {
+		return "", err
+	}
+	return ShowE(t, options)
+}
+
+// PlanAndShowWithStruct runs terraform plan with the given options, writing the plan to PlanFilePath, and then parses
+// the output of terraform show into a PlanStruct. This will fail the test if there is an error in either command or in
+// parsing the plan.
+func PlanAndShowWithStruct(t testing.TestingT, options *Options) *PlanStruct {
+	plan, err := PlanAndShowWithStructE(t, options)
+	require.NoError(t, err)
+	return plan
+}
+
+// PlanAndShowWithStructE runs terraform plan with the given options, writing the plan to PlanFilePath, and then parses
+// the output of terraform show into a PlanStruct.
+func PlanAndShowWithStructE(t testing.TestingT, options *Options) (*PlanStruct, error) {
+	json, err := PlanAndShowE(t, options)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePlanJSON(json)
+}
+
 // PlanExitCode runs terraform plan with the given options and returns the detailed exitcode.
 // This will fail the test if there is an error in the command.
 func PlanExitCode(t testing.TestingT, options *Options) int {
@@ -42,6 +83,7 @@ func PlanExitCodeE(t testing.TestingT, options *Options) (int, error) {
 
 // Custom errors
 var (
+	ErrorAddressRequired      = fmt.Errorf("you must pass at least one resource address to use this function")
 	ErrorComponentRequired    = fmt.Errorf("you must set Component on options struct to use this function")
 	ErrorPlanFilePathRequired = fmt.Errorf("you must set PlanFilePath on options struct to use this function")
 	ErrorStackRequired        = fmt.Errorf("you must set Stack on options struct to use this function")
@@ -0,0 +1,153 @@
+package atmos
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cloudposse/test-helpers/pkg/testing"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+)
+
+// AssertPlanCreates checks that the plan creates the resource at address. A replacement does not count as a create.
+func AssertPlanCreates(t testing.TestingT, plan *PlanStruct, address string) bool {
+	return assert.NoError(t, AssertPlanCreatesE(t, plan, address))
+}
+
+// AssertPlanCreatesE checks that the plan creates the resource at address. A replacement does not count as a create.
+func AssertPlanCreatesE(t testing.TestingT, plan *PlanStruct, address string) error {
+	change, err := resourceChange(plan, address)
+	if err != nil {
+		return err
+	}
+
+	if !change.Change.Actions.Create() {
+		return UnexpectedResourceChangeActions{Address: address, Expected: "create", Actions: actionStrings(change.Change.Actions)}
+	}
+	return nil
+}
+
+// AssertPlanAttribute checks that the planned value of the attribute at path of the resource at address equals
+// expected. The path separates nested attributes and list indexes with dots, e.g. "versioning.0.enabled". Numbers are
+// compared by value, so an int can be expected where the plan holds a float64.
+func AssertPlanAttribute(t testing.TestingT, plan *PlanStruct, address string, path string, expected interface{}) bool {
+	return assert.NoError(t, AssertPlanAttributeE(t, plan, address, path, expected))
+}
+
+// AssertPlanAttributeE checks that the planned value of the attribute at path of the resource at address equals
+// expected. The path separates nested attributes and list indexes with dots, e.g. "versioning.0.enabled".
+func AssertPlanAttributeE(t testing.TestingT, plan *PlanStruct, address string, path string, expected interface{}) error {
+	change, err := resourceChange(plan, address)
+	if err != nil {
+		return err
+	}
+
+	if attributeUnknown(change.Change.AfterUnknown, path) {
+		return PlanAttributeUnknown{Address: address, Path: path}
+	}
+
+	actual, ok := lookupAttribute(change.Change.After, path)
+	if !ok {
+		return PlanAttributeNotFound{Address: address, Path: path}
+	}
+
+	if !assert.ObjectsAreEqualValues(expected, actual) {
+		return PlanAttributeMismatch{Address: address, Path: path, Expected: expected, Actual: actual}
+	}
+	return nil
+}
+
+// AssertNoDestroys checks that the plan neither deletes nor replaces any resource.
+func AssertNoDestroys(t testing.TestingT, plan *PlanStruct) bool {
+	return assert.NoError(t, AssertNoDestroysE(t, plan))
+}
+
+// AssertNoDestroysE checks that the plan neither deletes nor replaces any resource.
+func AssertNoDestroysE(t testing.TestingT, plan *PlanStruct) error {
+	return assertPlanActions(plan, "create, update or read resources", func(actions tfjson.Actions) bool {
+		return !actions.Delete() && !actions.Replace()
+	})
+}
+
+// AssertPlanOnlyUpdatesInPlace checks that every resource change in the plan is an in-place update, a read or a no-op.
+// Creates, deletes and replacements fail the check.
+func AssertPlanOnlyUpdatesInPlace(t testing.TestingT, plan *PlanStruct) bool {
+	return assert.NoError(t, AssertPlanOnlyUpdatesInPlaceE(t, plan))
+}
+
+// AssertPlanOnlyUpdatesInPlaceE checks that every resource change in the plan is an in-place update, a read or a
+// no-op.
+func AssertPlanOnlyUpdatesInPlaceE(t testing.TestingT, plan *PlanStruct) error {
+	return assertPlanActions(plan, "update resources in place", func(actions tfjson.Actions) bool {
+		return actions.Update() || actions.Read() || actions.NoOp()
+	})
+}
+
+func assertPlanActions(plan *PlanStruct, allowed string, isAllowed func(actions tfjson.Actions) bool) error {
+	unexpected := map[string][]string{}
+	for address, change := range plan.ResourceChangesMap {
+		if change.Change == nil || isAllowed(change.Change.Actions) {
+			continue
+		}
+		unexpected[address] = actionStrings(change.Change.Actions)
+	}
+
+	if len(unexpected) > 0 {
+		return PlanHasUnexpectedChanges{Allowed: allowed, Changes: unexpected}
+	}
+	return nil
+}
+
+func resourceChange(plan *PlanStruct, address string) (*tfjson.ResourceChange, error) {
+	change, ok := plan.ResourceChangesMap[address]
+	if !ok || change.Change == nil {
+		return nil, ResourceChangeNotFound(address)
+	}
+	return change, nil
+}
+
+// lookupAttribute walks the planned values along path, using list indexes for list elements.
+func lookupAttribute(value interface{}, path string) (interface{}, bool) {
+	for _, segment := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			next, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// attributeUnknown reports whether the attribute at path, or one of its parents, is marked as known only after apply.
+func attributeUnknown(afterUnknown interface{}, path string) bool {
+	segments := strings.Split(path, ".")
+	for i := range segments {
+		value, ok := lookupAttribute(afterUnknown, strings.Join(segments[:i+1], "."))
+		if !ok {
+			return false
+		}
+		if unknown, _ := value.(bool); unknown {
+			return true
+		}
+	}
+	return false
+}
+
+func actionStrings(actions tfjson.Actions) []string {
+	result := make([]string, 0, len(actions))
+	for _, action := range actions {
+		result = append(result, string(action))
+	}
+	return result
+}
@@ -0,0 +1,109 @@
+package atmos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPlanJSON = `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {
+      "address": "aws_s3_bucket.default",
+      "type": "aws_s3_bucket",
+      "name": "default",
+      "change": {
+        "actions": ["create"],
+        "before": null,
+        "after": {"bucket": "eg-test-abc123", "versioning": [{"enabled": true, "mfa_delete": false}], "tags": {"Attributes": "abc123"}},
+        "after_unknown": {"arn": true, "versioning": [{}], "tags": {}}
+      }
+    },
+    {
+      "address": "aws_s3_bucket_policy.default",
+      "type": "aws_s3_bucket_policy",
+      "name": "default",
+      "change": {
+        "actions": ["update"],
+        "before": {"policy": "{}"},
+        "after": {"policy": "{\"Version\":\"2012-10-17\"}", "max_age": 3600},
+        "after_unknown": {}
+      }
+    },
+    {
+      "address": "aws_iam_role.default",
+      "type": "aws_iam_role",
+      "name": "default",
+      "change": {
+        "actions": ["delete", "create"],
+        "before": {"name": "old"},
+        "after": {"name": "new"},
+        "after_unknown": {"arn": true}
+      }
+    }
+  ]
+}`
+
+func parseTestPlan(t *testing.T) *PlanStruct {
+	plan, err := ParsePlanJSON(testPlanJSON)
+	require.NoError(t, err)
+	return plan
+}
+
+func TestAssertPlanCreates(t *testing.T) {
+	plan := parseTestPlan(t)
+
+	require.NoError(t, AssertPlanCreatesE(t, plan, "aws_s3_bucket.default"))
+
+	err := AssertPlanCreatesE(t, plan, "aws_iam_role.default")
+	require.ErrorAs(t, err, &UnexpectedResourceChangeActions{})
+	assert.Equal(t, []string{"delete", "create"}, err.(UnexpectedResourceChangeActions).Actions)
+
+	err = AssertPlanCreatesE(t, plan, "aws_s3_bucket.missing")
+	assert.Equal(t, ResourceChangeNotFound("aws_s3_bucket.missing"), err)
+}
+
+func TestAssertPlanAttribute(t *testing.T) {
+	plan := parseTestPlan(t)
+
+	require.NoError(t, AssertPlanAttributeE(t, plan, "aws_s3_bucket.default", "versioning.0.enabled", true))
+	require.NoError(t, AssertPlanAttributeE(t, plan, "aws_s3_bucket.default", "tags.Attributes", "abc123"))
+	require.NoError(t, AssertPlanAttributeE(t, plan, "aws_s3_bucket_policy.default", "max_age", 3600))
+
+	err := AssertPlanAttributeE(t, plan, "aws_s3_bucket.default", "versioning.0.mfa_delete", true)
+	assert.Equal(t, PlanAttributeMismatch{Address: "aws_s3_bucket.default", Path: "versioning.0.mfa_delete", Expected: true, Actual: false}, err)
+
+	err = AssertPlanAttributeE(t, plan, "aws_s3_bucket.default", "arn", "arn:aws:s3:::eg-test-abc123")
+	assert.Equal(t, PlanAttributeUnknown{Address: "aws_s3_bucket.default", Path: "arn"}, err)
+
+	err = AssertPlanAttributeE(t, plan, "aws_s3_bucket.default", "versioning.1.enabled", true)
+	assert.Equal(t, PlanAttributeNotFound{Address: "aws_s3_bucket.default", Path: "versioning.1.enabled"}, err)
+}
+
+func TestAssertNoDestroys(t *testing.T) {
+	plan := parseTestPlan(t)
+
+	err := AssertNoDestroysE(t, plan)
+	require.ErrorAs(t, err, &PlanHasUnexpectedChanges{})
+	assert.Equal(t, map[string][]string{"aws_iam_role.default": {"delete", "create"}}, err.(PlanHasUnexpectedChanges).Changes)
+
+	delete(plan.ResourceChangesMap, "aws_iam_role.default")
+	assert.NoError(t, AssertNoDestroysE(t, plan))
+}
+
+func TestAssertPlanOnlyUpdatesInPlace(t *testing.T) {
+	plan := parseTestPlan(t)
+
+	err := AssertPlanOnlyUpdatesInPlaceE(t, plan)
+	require.ErrorAs(t, err, &PlanHasUnexpectedChanges{})
+	assert.Equal(t, map[string][]string{
+		"aws_s3_bucket.default": {"create"},
+		"aws_iam_role.default":  {"delete", "create"},
+	}, err.(PlanHasUnexpectedChanges).Changes)
+
+	delete(plan.ResourceChangesMap, "aws_s3_bucket.default")
+	delete(plan.ResourceChangesMap, "aws_iam_role.default")
+	assert.NoError(t, AssertPlanOnlyUpdatesInPlaceE(t, plan))
+}
@@ -1,7 +1,9 @@
 package atmos
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/gruntwork-io/terratest/modules/files"
@@ -65,3 +67,41 @@ func TestPlanWithExitCodeWithFailure(t *testing.T) {
 	require.NoError(t, getExitCodeErr)
 	require.Equal(t, exitCode, 1)
 }
+
+func TestPlanAndShowWithStruct(t *testing.T) {
+	t.Parallel()
+
+	testFolder, err := files.CopyTerraformFolderToTemp(atmosExamplePath, t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(testFolder)
+
+	options := &Options{
+		AtmosBasePath: testFolder,
+		Component:     "terraform-basic-configuration",
+		Stack:         testStack,
+		Vars: map[string]interface{}{
+			"cnt": 3,
+		},
+		PlanFilePath: filepath.Join(testFolder, "plan.out"),
+	}
+
+	plan := PlanAndShowWithStruct(t, options)
+	require.Len(t, plan.ResourceChangesMap, 3)
+	for i := 0; i < 3; i++ {
+		address := fmt.Sprintf("null_resource.test[%d]", i)
+		require.Contains(t, plan.ResourceChangesMap, address)
+		require.True(t, plan.ResourceChangesMap[address].Change.Actions.Create())
+	}
+}
+
+func TestPlanAndShowWithStructRequiresPlanFilePath(t *testing.T) {
+	t.Parallel()
+
+	options := &Options{
+		Component: "terraform-basic-configuration",
+		Stack:     testStack,
+	}
+
+	_, err := PlanAndShowWithStructE(t, options)
+	require.ErrorIs(t, err, ErrorPlanFilePathRequired)
+}
@@ -0,0 +1,67 @@
+package atmos
+
+import (
+	"github.com/cloudposse/test-helpers/pkg/testing"
+	tt "github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+// PlanStruct is a Go Struct representation of the plan object returned from Terraform (after running `atmos terraform
+// show`). It maps the resource addresses to the changes and planned values to make it easier to navigate the raw plan.
+type PlanStruct = tt.PlanStruct
+
+// ParsePlanJSON takes in the json string representation of the terraform plan and returns a go struct representation
+// for easy introspection.
+func ParsePlanJSON(jsonStr string) (*PlanStruct, error) {
+	return tt.ParsePlanJSON(jsonStr)
+}
+
+// Show calls atmos terraform show in json mode with the given options and returns stdout from the command. The plan
+// file at PlanFilePath is shown. This will fail the test if there is an error in the command.
+func Show(t testing.TestingT, options *Options) string {
+	out, err := ShowE(t, options)
+	require.NoError(t, err)
+	return out
+}
+
+// ShowE calls atmos terraform show in json mode with the given options and returns stdout from the command. The plan
+// file at PlanFilePath is shown.
+func ShowE(t testing.TestingT, options *Options) (string, error) {
+	if options.Component == "" {
+		return "", ErrorComponentRequired
+	}
+
+	if options.Stack == "" {
+		return "", ErrorStackRequired
+	}
+
+	if options.PlanFilePath == "" {
+		return "", ErrorPlanFilePathRequired
+	}
+
+	// We manually construct the args here instead of using `FormatArgs`, because show only accepts a limited set of
+	// args.
+	args := []string{"terraform", "show", options.Component, "--skip-init", "-s", options.Stack, "--", "-no-color", "-json", options.PlanFilePath}
+	out, err := RunAtmosCommandAndGetStdoutE(t, options, args...)
+	if err != nil {
+		return "", err
+	}
+	return cleanOutput(out), nil
+}
+
+// ShowWithStruct calls atmos terraform show in json mode with the given options and returns the parsed plan. This will
+// fail the test if there is an error in the command or in parsing the plan.
+func ShowWithStruct(t testing.TestingT, options *Options) *PlanStruct {
+	out, err := ShowWithStructE(t, options)
+	require.NoError(t, err)
+	return out
+}
+
+// ShowWithStructE calls atmos terraform show in json mode with the given options and returns the parsed plan.
+func ShowWithStructE(t testing.TestingT, options *Options) (*PlanStruct, error) {
+	json, err := ShowE(t, options)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePlanJSON(json)
+}
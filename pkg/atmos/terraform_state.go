@@ -0,0 +1,178 @@
+package atmos
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/cloudposse/test-helpers/pkg/testing"
+	tt "github.com/gruntwork-io/terratest/modules/terraform"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/require"
+)
+
+// stateAddress matches the lines of terraform state list that are resource addresses, as opposed to the lines atmos
+// and terraform print before them, such as "Switched to workspace".
+var stateAddress = regexp.MustCompile(`^(module|data|[A-Za-z_][A-Za-z0-9_-]*)\.[A-Za-z_]`)
+
+// Import runs atmos terraform import with the given options to import the existing infrastructure object with the
+// given id into the resource at address, and returns stdout/stderr. This will fail the test if the import fails.
+func Import(t testing.TestingT, options *Options, address string, id string) string {
+	out, err := ImportE(t, options, address, id)
+	require.NoError(t, err)
+	return out
+}
+
+// ImportE runs atmos terraform import with the given options to import the existing infrastructure object with the
+// given id into the resource at address, and returns stdout/stderr.
+func ImportE(t testing.TestingT, options *Options, address string, id string) (string, error) {
+	if options.Component == "" {
+		return "", ErrorComponentRequired
+	}
+
+	if options.Stack == "" {
+		return "", ErrorStackRequired
+	}
+
+	// We manually construct the args here instead of using `FormatArgs`, because terraform requires the address and id
+	// to come after every option.
+	args := []string{"terraform", "import", options.Component, "-s", options.Stack, "-input=false"}
+	args = append(args, tt.FormatTerraformVarsAsArgs(options.Vars)...)
+	args = append(args, tt.FormatTerraformArgs("-var-file", options.VarFiles)...)
+	args = append(args, tt.FormatTerraformLockAsArgs(options.Lock, options.LockTimeout)...)
+	if options.NoColor {
+		args = append(args, "-no-color")
+	}
+	args = append(args, address, id)
+
+	return RunAtmosCommandE(t, options, args...)
+}
+
+// StateList runs atmos terraform state list with the given options and returns the addresses of the resources in the
+// state. If addresses are given, only the resources they match are returned. This will fail the test if there is an
+// error in the command.
+func StateList(t testing.TestingT, options *Options, addresses ...string) []string {
+	out, err := StateListE(t, options, addresses...)
+	require.NoError(t, err)
+	return out
+}
+
+// StateListE runs atmos terraform state list with the given options and returns the addresses of the resources in the
+// state. If addresses are given, only the resources they match are returned.
+func StateListE(t testing.TestingT, options *Options, addresses ...string) ([]string, error) {
+	if options.Component == "" {
+		return nil, ErrorComponentRequired
+	}
+
+	if options.Stack == "" {
+		return nil, ErrorStackRequired
+	}
+
+	args := append([]string{"terraform", "state", "list", options.Component, "-s", options.Stack}, addresses...)
+	out, err := RunAtmosCommandAndGetStdoutE(t, options, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseStateList(out), nil
+}
+
+// parseStateList returns the resource addresses in the output of terraform state list.
+func parseStateList(out string) []string {
+	addresses := []string{}
+	for _, line := range strings.Split(escapeSequence.ReplaceAllString(out, ""), "\n") {
+		line = strings.TrimSpace(line)
+		if stateAddress.MatchString(line) {
+			addresses = append(addresses, line)
+		}
+	}
+	return addresses
+}
+
+// StateShow returns the attributes of the resource at address in the state of the component, as terraform show -json
+// reports them. This will fail the test if there is an error in the command or the resource is not in the state.
+func StateShow(t testing.TestingT, options *Options, address string) map[string]interface{} {
+	out, err := StateShowE(t, options, address)
+	require.NoError(t, err)
+	return out
+}
+
+// StateShowE returns the attributes of the resource at address in the state of the component, as terraform show -json
+// reports them. A ResourceNotInState error is returned if the resource is not in the state.
+func StateShowE(t testing.TestingT, options *Options, address string) (map[string]interface{}, error) {
+	if options.Component == "" {
+		return nil, ErrorComponentRequired
+	}
+
+	if options.Stack == "" {
+		return nil, ErrorStackRequired
+	}
+
+	// terraform state show only prints the resource in HCL, so the whole state is read as json instead.
+	args := []string{"terraform", "show", options.Component, "-s", options.Stack, "--", "-no-color", "-json"}
+	out, err := RunAtmosCommandAndGetStdoutE(t, options, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseStateResourceAttributes(cleanOutput(out), address)
+}
+
+// parseStateResourceAttributes returns the attributes of the resource at address in the json state.
+func parseStateResourceAttributes(out string, address string) (map[string]interface{}, error) {
+	state := &tfjson.State{}
+	if err := json.Unmarshal([]byte(out), state); err != nil {
+		return nil, err
+	}
+
+	if state.Values != nil {
+		if resource := findStateResource(state.Values.RootModule, address); resource != nil {
+			return resource.AttributeValues, nil
+		}
+	}
+	return nil, ResourceNotInState(address)
+}
+
+func findStateResource(module *tfjson.StateModule, address string) *tfjson.StateResource {
+	if module == nil {
+		return nil
+	}
+	for _, resource := range module.Resources {
+		if resource.Address == address {
+			return resource
+		}
+	}
+	for _, child := range module.ChildModules {
+		if resource := findStateResource(child, address); resource != nil {
+			return resource
+		}
+	}
+	return nil
+}
+
+// StateRm runs atmos terraform state rm with the given options to remove the resources at addresses from the state
+// without destroying them, and returns stdout/stderr. This will fail the test if there is an error in the command.
+func StateRm(t testing.TestingT, options *Options, addresses ...string) string {
+	out, err := StateRmE(t, options, addresses...)
+	require.NoError(t, err)
+	return out
+}
+
+// StateRmE runs atmos terraform state rm with the given options to remove the resources at addresses from the state
+// without destroying them, and returns stdout/stderr.
+func StateRmE(t testing.TestingT, options *Options, addresses ...string) (string, error) {
+	if options.Component == "" {
+		return "", ErrorComponentRequired
+	}
+
+	if options.Stack == "" {
+		return "", ErrorStackRequired
+	}
+
+	if len(addresses) == 0 {
+		return "", ErrorAddressRequired
+	}
+
+	args := []string{"terraform", "state", "rm", options.Component, "-s", options.Stack}
+	args = append(args, tt.FormatTerraformLockAsArgs(options.Lock, options.LockTimeout)...)
+	args = append(args, addresses...)
+	return RunAtmosCommandE(t, options, args...)
+}
@@ -0,0 +1,109 @@
+package atmos
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportE(t *testing.T) {
+	executor := NewFakeExecutor(FakeResponse{Stdout: "Import successful!"})
+	options := &Options{
+		Component: "vpc",
+		Stack:     "default-test",
+		Vars:      map[string]interface{}{"enabled": true},
+		NoColor:   true,
+		Executor:  executor,
+	}
+
+	out, err := ImportE(t, options, "aws_vpc.default[0]", "vpc-123")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Import successful!")
+
+	call, ok := executor.LastCall()
+	require.True(t, ok)
+	assert.Equal(t, []string{
+		"terraform", "import", "vpc", "-s", "default-test", "-input=false", "-var", "enabled=true", "-lock=false", "-no-color",
+		"aws_vpc.default[0]", "vpc-123",
+	}, call.Args)
+}
+
+func TestStateListE(t *testing.T) {
+	executor := NewFakeExecutor(FakeResponse{
+		Stdout: "Switched to workspace \"default-test\".\n" +
+			"aws_vpc.default[0]\n" +
+			"data.aws_region.current\n" +
+			"module.subnets.aws_subnet.private[\"us-east-2a\"]\n",
+		Stderr: "INFO Running terraform state list",
+	})
+	options := &Options{Component: "vpc", Stack: "default-test", Executor: executor}
+
+	addresses, err := StateListE(t, options, "module.subnets")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"aws_vpc.default[0]",
+		"data.aws_region.current",
+		"module.subnets.aws_subnet.private[\"us-east-2a\"]",
+	}, addresses)
+
+	call, ok := executor.LastCall()
+	require.True(t, ok)
+	assert.Equal(t, []string{"terraform", "state", "list", "vpc", "-s", "default-test", "module.subnets"}, call.Args)
+}
+
+func TestStateShowE(t *testing.T) {
+	state := `{
+  "format_version": "1.0",
+  "terraform_version": "1.9.0",
+  "values": {
+    "root_module": {
+      "resources": [
+        {"address": "aws_vpc.default[0]", "mode": "managed", "type": "aws_vpc", "name": "default", "values": {"id": "vpc-123", "cidr_block": "10.0.0.0/16"}}
+      ],
+      "child_modules": [
+        {
+          "address": "module.subnets",
+          "resources": [
+            {"address": "module.subnets.aws_subnet.private[0]", "mode": "managed", "type": "aws_subnet", "name": "private", "values": {"id": "subnet-123"}}
+          ]
+        }
+      ]
+    }
+  }
+}`
+	options := &Options{
+		Component: "vpc",
+		Stack:     "default-test",
+		Executor:  NewFakeExecutor(FakeResponse{Stdout: "Switched to workspace \"default-test\".\n" + state}),
+	}
+
+	attributes, err := StateShowE(t, options, "aws_vpc.default[0]")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/16", attributes["cidr_block"])
+
+	attributes, err = StateShowE(t, options, "module.subnets.aws_subnet.private[0]")
+	require.NoError(t, err)
+	assert.Equal(t, "subnet-123", attributes["id"])
+
+	_, err = StateShowE(t, options, "aws_vpc.missing")
+	var notInState ResourceNotInState
+	require.True(t, errors.As(err, &notInState))
+	assert.Equal(t, ResourceNotInState("aws_vpc.missing"), notInState)
+}
+
+func TestStateRmE(t *testing.T) {
+	executor := NewFakeExecutor(FakeResponse{Stdout: "Removed aws_vpc.default[0]"})
+	options := &Options{Component: "vpc", Stack: "default-test", Executor: executor}
+
+	_, err := StateRmE(t, options)
+	assert.Equal(t, ErrorAddressRequired, err)
+
+	_, err = StateRmE(t, options, "aws_vpc.default[0]", "aws_subnet.private")
+	require.NoError(t, err)
+
+	call, ok := executor.LastCall()
+	require.True(t, ok)
+	assert.Equal(t, []string{"terraform", "state", "rm", "vpc", "-s", "default-test", "-lock=false", "aws_vpc.default[0]", "aws_subnet.private"}, call.Args)
+}
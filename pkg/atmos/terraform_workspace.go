@@ -0,0 +1,131 @@
+package atmos
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/cloudposse/test-helpers/pkg/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// workspaceListEntry matches the lines of terraform workspace list, which prints every workspace on its own line and
+// marks the current one with "*". Lines that terraform init prints before the list don't match.
+var workspaceListEntry = regexp.MustCompile(`^([* ]) (\S+)$`)
+
+// WorkspaceSelectOrNew runs atmos terraform workspace select for the component in the stack, creating the workspace
+// with the given name if it does not exist yet, and returns the name of the workspace. This will fail the test if there
+// is an error.
+func WorkspaceSelectOrNew(t testing.TestingT, options *Options, name string) string {
+	out, err := WorkspaceSelectOrNewE(t, options, name)
+	require.NoError(t, err)
+	return out
+}
+
+// WorkspaceSelectOrNewE runs atmos terraform workspace select for the component in the stack, creating the workspace
+// with the given name if it does not exist yet, and returns the name of the workspace.
+func WorkspaceSelectOrNewE(t testing.TestingT, options *Options, name string) (string, error) {
+	workspaces, current, err := listWorkspaces(t, options)
+	if err != nil {
+		return "", err
+	}
+
+	if current == name {
+		return name, nil
+	}
+
+	subCommand := "new"
+	if slices.Contains(workspaces, name) {
+		subCommand = "select"
+	}
+	if _, err := RunAtmosCommandE(t, options, "terraform", "workspace", subCommand, options.Component, "-s", options.Stack, name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// WorkspaceList runs atmos terraform workspace list for the component in the stack and returns the names of the
+// workspaces. This will fail the test if there is an error.
+func WorkspaceList(t testing.TestingT, options *Options) []string {
+	out, err := WorkspaceListE(t, options)
+	require.NoError(t, err)
+	return out
+}
+
+// WorkspaceListE runs atmos terraform workspace list for the component in the stack and returns the names of the
+// workspaces.
+func WorkspaceListE(t testing.TestingT, options *Options) ([]string, error) {
+	workspaces, _, err := listWorkspaces(t, options)
+	return workspaces, err
+}
+
+// WorkspaceDelete runs atmos terraform workspace delete for the component in the stack to delete the workspace with the
+// given name, and returns stdout/stderr. This will fail the test if there is an error.
+func WorkspaceDelete(t testing.TestingT, options *Options, name string) string {
+	out, err := WorkspaceDeleteE(t, options, name)
+	require.NoError(t, err)
+	return out
+}
+
+// WorkspaceDeleteE runs atmos terraform workspace delete for the component in the stack to delete the workspace with
+// the given name, and returns stdout/stderr. If the workspace is the current one, "default" is selected first, since
+// terraform does not delete the current workspace. Deleting "default" returns UnsupportedDefaultWorkspaceDeletion, and
+// deleting a workspace that does not exist returns WorkspaceDoesNotExist.
+func WorkspaceDeleteE(t testing.TestingT, options *Options, name string) (string, error) {
+	if name == "default" {
+		return "", &UnsupportedDefaultWorkspaceDeletion{}
+	}
+
+	workspaces, current, err := listWorkspaces(t, options)
+	if err != nil {
+		return "", err
+	}
+
+	if !slices.Contains(workspaces, name) {
+		return "", WorkspaceDoesNotExist(name)
+	}
+
+	if current == name {
+		if _, err := RunAtmosCommandE(t, options, "terraform", "workspace", "select", options.Component, "-s", options.Stack, "default"); err != nil {
+			return "", err
+		}
+	}
+
+	return RunAtmosCommandE(t, options, "terraform", "workspace", "delete", options.Component, "-s", options.Stack, name)
+}
+
+// listWorkspaces returns the workspaces of the component in the stack and the name of the current one.
+func listWorkspaces(t testing.TestingT, options *Options) ([]string, string, error) {
+	if options.Component == "" {
+		return nil, "", ErrorComponentRequired
+	}
+
+	if options.Stack == "" {
+		return nil, "", ErrorStackRequired
+	}
+
+	out, err := RunAtmosCommandAndGetStdoutE(t, options, "terraform", "workspace", "list", options.Component, "-s", options.Stack)
+	if err != nil {
+		return nil, "", err
+	}
+
+	workspaces, current := parseWorkspaceList(out)
+	return workspaces, current, nil
+}
+
+// parseWorkspaceList returns the workspaces in the output of terraform workspace list and the name of the current one.
+func parseWorkspaceList(out string) ([]string, string) {
+	workspaces := []string{}
+	current := ""
+	for _, line := range strings.Split(escapeSequence.ReplaceAllString(out, ""), "\n") {
+		match := workspaceListEntry.FindStringSubmatch(strings.TrimRight(line, " \r"))
+		if match == nil {
+			continue
+		}
+		workspaces = append(workspaces, match[2])
+		if match[1] == "*" {
+			current = match[2]
+		}
+	}
+	return workspaces, current
+}
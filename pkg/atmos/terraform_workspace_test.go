@@ -0,0 +1,68 @@
+package atmos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const workspaceListOutput = "Initializing the backend...\n" +
+	"Terraform has been successfully initialized!\n" +
+	"  default\n" +
+	"* default-test\n" +
+	"  run-1234\n"
+
+func TestWorkspaceListE(t *testing.T) {
+	options := &Options{
+		Component: "vpc",
+		Stack:     "default-test",
+		Executor:  NewFakeExecutor(FakeResponse{Stdout: workspaceListOutput}),
+	}
+
+	workspaces, err := WorkspaceListE(t, options)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default", "default-test", "run-1234"}, workspaces)
+}
+
+func TestWorkspaceSelectOrNewE(t *testing.T) {
+	list := FakeResponse{Args: []string{"terraform", "workspace", "list"}, Stdout: workspaceListOutput}
+	executor := NewFakeExecutor(list, list, list, FakeResponse{Args: []string{"terraform", "workspace"}})
+	options := &Options{Component: "vpc", Stack: "default-test", Executor: executor}
+
+	name, err := WorkspaceSelectOrNewE(t, options, "run-1234")
+	require.NoError(t, err)
+	assert.Equal(t, "run-1234", name)
+	call, _ := executor.LastCall()
+	assert.Equal(t, []string{"terraform", "workspace", "select", "vpc", "-s", "default-test", "run-1234"}, call.Args)
+
+	_, err = WorkspaceSelectOrNewE(t, options, "run-5678")
+	require.NoError(t, err)
+	call, _ = executor.LastCall()
+	assert.Equal(t, []string{"terraform", "workspace", "new", "vpc", "-s", "default-test", "run-5678"}, call.Args)
+
+	calls := len(executor.Calls())
+	_, err = WorkspaceSelectOrNewE(t, options, "default-test")
+	require.NoError(t, err)
+	assert.Len(t, executor.Calls(), calls+1)
+}
+
+func TestWorkspaceDeleteE(t *testing.T) {
+	list := FakeResponse{Args: []string{"terraform", "workspace", "list"}, Stdout: workspaceListOutput}
+	executor := NewFakeExecutor(list, list, list, FakeResponse{Args: []string{"terraform", "workspace"}})
+	options := &Options{Component: "vpc", Stack: "default-test", Executor: executor}
+
+	_, err := WorkspaceDeleteE(t, options, "default")
+	assert.Equal(t, &UnsupportedDefaultWorkspaceDeletion{}, err)
+	assert.Empty(t, executor.Calls())
+
+	_, err = WorkspaceDeleteE(t, options, "missing")
+	assert.Equal(t, WorkspaceDoesNotExist("missing"), err)
+
+	_, err = WorkspaceDeleteE(t, options, "default-test")
+	require.NoError(t, err)
+	calls := executor.Calls()
+	require.Len(t, calls, 4)
+	assert.Equal(t, []string{"terraform", "workspace", "select", "vpc", "-s", "default-test", "default"}, calls[2].Args)
+	assert.Equal(t, []string{"terraform", "workspace", "delete", "vpc", "-s", "default-test", "default-test"}, calls[3].Args)
+}
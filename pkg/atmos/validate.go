@@ -0,0 +1,113 @@
+package atmos
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/cloudposse/test-helpers/pkg/testing"
+	"github.com/stretchr/testify/require"
+)
+
+var stackManifestRegexp = regexp.MustCompile(`stack manifest '([^']+)'`)
+
+// ValidateStacks runs atmos validate stacks with the given options and returns stdout/stderr. This will fail the test
+// if any stack manifest is invalid.
+func ValidateStacks(t testing.TestingT, options *Options) string {
+	out, err := ValidateStacksE(t, options)
+	require.NoError(t, err)
+	return out
+}
+
+// ValidateStacksE runs atmos validate stacks with the given options and returns stdout/stderr. If validation fails,
+// the returned error is a StacksValidationFailed listing the failures for each stack manifest. Errors that do not come
+// from atmos exiting with a non-zero exit code, such as a missing atmos binary or a timeout, are returned as they are.
+func ValidateStacksE(t testing.TestingT, options *Options) (string, error) {
+	out, err := RunAtmosCommandE(t, options, "validate", "stacks")
+	if isValidationFailure(err) {
+		return out, StacksValidationFailed{Errors: parseStackValidationErrors(out, err), Underlying: err}
+	}
+	return out, err
+}
+
+// ValidateComponent runs atmos validate component for the Component and Stack set on the options and returns
+// stdout/stderr. This will fail the test if the component does not pass its JSON Schema or OPA policy validation.
+func ValidateComponent(t testing.TestingT, options *Options) string {
+	out, err := ValidateComponentE(t, options)
+	require.NoError(t, err)
+	return out
+}
+
+// ValidateComponentE runs atmos validate component for the Component and Stack set on the options and returns
+// stdout/stderr. If validation fails, the returned error is a ComponentValidationFailed. Like ValidateStacksE, only a
+// non-zero exit code of atmos is reported as a validation failure.
+func ValidateComponentE(t testing.TestingT, options *Options) (string, error) {
+	if options.Component == "" {
+		return "", ErrorComponentRequired
+	}
+
+	if options.Stack == "" {
+		return "", ErrorStackRequired
+	}
+
+	out, err := RunAtmosCommandE(t, options, "validate", "component", options.Component, "-s", options.Stack)
+	if isValidationFailure(err) {
+		return out, ComponentValidationFailed{
+			Component:  options.Component,
+			Stack:      options.Stack,
+			Messages:   validationMessages(out, err),
+			Underlying: err,
+		}
+	}
+	return out, err
+}
+
+// isValidationFailure returns whether err comes from atmos exiting with a non-zero exit code, rather than from atmos
+// not running at all or being killed.
+func isValidationFailure(err error) bool {
+	var timedOut CommandTimedOut
+	if err == nil || errors.As(err, &timedOut) {
+		return false
+	}
+	var exitErr exitCoder
+	return errors.As(err, &exitErr) && exitErr.ExitCode() != DefaultSuccessExitCode
+}
+
+// parseStackValidationErrors groups the messages in the output of atmos validate stacks by the stack manifest they
+// refer to. Messages that precede any stack manifest reference are grouped under an empty manifest name.
+func parseStackValidationErrors(out string, err error) []StackValidationError {
+	var result []StackValidationError
+	current := -1
+
+	for _, line := range validationMessages(out, err) {
+		if m := stackManifestRegexp.FindStringSubmatch(line); m != nil {
+			result = append(result, StackValidationError{Manifest: m[1]})
+			current = len(result) - 1
+		}
+		if current < 0 {
+			result = append(result, StackValidationError{})
+			current = 0
+		}
+		result[current].Messages = append(result[current].Messages, line)
+	}
+
+	return result
+}
+
+// validationMessages returns the meaningful lines of a failed validate command, dropping blank lines and log noise.
+// If the command produced no output, the command error itself is used as the message.
+func validationMessages(out string, err error) []string {
+	var messages []string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.Contains(line, "INFO") || strings.Contains(line, "::debug::") {
+			continue
+		}
+		messages = append(messages, line)
+	}
+
+	if len(messages) == 0 && err != nil {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
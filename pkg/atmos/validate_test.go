@@ -0,0 +1,117 @@
+package atmos
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/files"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateStacksWithInvalidManifest(t *testing.T) {
+	t.Parallel()
+
+	testFolder, err := files.CopyTerraformFolderToTemp(atmosExamplePath, t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(testFolder)
+
+	invalidManifest := filepath.Join(testFolder, "stacks", "orgs", "invalid.yaml")
+	err = os.WriteFile(invalidManifest, []byte("components:\n  terraform: [\n"), 0644)
+	require.NoError(t, err)
+
+	options := &Options{
+		AtmosBasePath: testFolder,
+	}
+
+	_, err = ValidateStacksE(t, options)
+	require.Error(t, err)
+
+	var validationErr StacksValidationFailed
+	require.True(t, errors.As(err, &validationErr), "expected a StacksValidationFailed but got %v", err)
+
+	var manifests []string
+	for _, e := range validationErr.Errors {
+		manifests = append(manifests, e.Manifest)
+	}
+	require.Contains(t, manifests, "orgs/invalid.yaml")
+}
+
+func TestValidateStacksEReportsNonZeroExitCode(t *testing.T) {
+	t.Parallel()
+
+	executor := NewFakeExecutor(FakeResponse{
+		Args:     []string{"validate", "stacks"},
+		Stderr:   "invalid stack manifest 'orgs/invalid.yaml'\nyaml: line 2: did not find expected node content\n",
+		ExitCode: 1,
+	})
+
+	_, err := ValidateStacksE(t, &Options{Executor: executor})
+
+	var validationErr StacksValidationFailed
+	require.True(t, errors.As(err, &validationErr))
+	require.Equal(t, []StackValidationError{{
+		Manifest: "orgs/invalid.yaml",
+		Messages: []string{"invalid stack manifest 'orgs/invalid.yaml'", "yaml: line 2: did not find expected node content"},
+	}}, validationErr.Errors)
+
+	var exitErr FakeExitError
+	require.True(t, errors.As(err, &exitErr), "the error of the command is reachable through Unwrap")
+}
+
+func TestValidateStacksEReturnsErrorsOtherThanValidationFailures(t *testing.T) {
+	t.Parallel()
+
+	_, err := ValidateStacksE(t, &Options{Executor: NewFakeExecutor()})
+	require.Error(t, err)
+
+	var validationErr StacksValidationFailed
+	require.False(t, errors.As(err, &validationErr))
+	var noResponse NoFakeResponse
+	require.True(t, errors.As(err, &noResponse))
+}
+
+func TestValidateComponentEReportsNonZeroExitCode(t *testing.T) {
+	t.Parallel()
+
+	executor := NewFakeExecutor(FakeResponse{
+		Args:     []string{"validate", "component", "vpc"},
+		Stderr:   "- at '/vars/cidr_block': got number, want string\n",
+		ExitCode: 1,
+	})
+
+	_, err := ValidateComponentE(t, &Options{Component: "vpc", Stack: "test", Executor: executor})
+
+	var validationErr ComponentValidationFailed
+	require.True(t, errors.As(err, &validationErr))
+	require.Equal(t, []string{"- at '/vars/cidr_block': got number, want string"}, validationErr.Messages)
+
+	_, err = ValidateComponentE(t, &Options{Component: "vpc", Stack: "test", Executor: NewFakeExecutor()})
+	require.False(t, errors.As(err, &validationErr))
+}
+
+func TestParseStackValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	out := `INFO Validating all YAML files in the 'stacks' folder
+invalid stack manifest 'orgs/invalid.yaml'
+yaml: line 3: did not find expected node content
+
+the stack manifest 'orgs/test.yaml' has errors
+- at '/components/terraform/vpc/vars': got string, want object
+`
+	result := parseStackValidationErrors(out, nil)
+	require.Len(t, result, 2)
+	require.Equal(t, "orgs/invalid.yaml", result[0].Manifest)
+	require.Equal(t, []string{"invalid stack manifest 'orgs/invalid.yaml'", "yaml: line 3: did not find expected node content"}, result[0].Messages)
+	require.Equal(t, "orgs/test.yaml", result[1].Manifest)
+	require.Len(t, result[1].Messages, 2)
+}
+
+func TestParseStackValidationErrorsWithoutOutput(t *testing.T) {
+	t.Parallel()
+
+	result := parseStackValidationErrors("", errors.New("exit status 1"))
+	require.Equal(t, []StackValidationError{{Messages: []string{"exit status 1"}}}, result)
+}
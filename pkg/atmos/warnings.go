@@ -0,0 +1,158 @@
+package atmos
+
+import (
+	"strings"
+
+	"github.com/cloudposse/test-helpers/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+// Warning is a warning terraform printed while running a command.
+type Warning struct {
+	Summary string // The first line of the warning, e.g. "Argument is deprecated"
+	Detail  string // The explanation that follows the source snippet, if any
+	Address string // The address of the resource the warning is about, if any, e.g. "aws_s3_bucket.default[0]"
+}
+
+// IsDeprecation returns true if the warning is about something deprecated, such as a provider argument.
+func (w Warning) IsDeprecation() bool {
+	return strings.Contains(strings.ToLower(w.Summary), "deprecated") ||
+		strings.Contains(strings.ToLower(w.Detail), "deprecated")
+}
+
+const (
+	diagnosticBoxStart = "╷"
+	diagnosticBoxLine  = "│"
+	warningPrefix      = "Warning: "
+)
+
+// ParseWarnings returns the warnings in the output of terraform, which prints them as blocks that start with
+// "Warning:". Both the boxed blocks of colored output and the plain blocks of -no-color output are understood.
+func ParseWarnings(out string) []Warning {
+	lines := strings.Split(escapeSequence.ReplaceAllString(out, ""), "\n")
+
+	var warnings []Warning
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \r")
+		boxed := strings.HasPrefix(line, diagnosticBoxLine)
+		if boxed {
+			line = strings.TrimPrefix(strings.TrimPrefix(line, diagnosticBoxLine), " ")
+		}
+		if !strings.HasPrefix(line, warningPrefix) {
+			continue
+		}
+
+		var body []string
+		if boxed {
+			body, i = boxedDiagnosticBody(lines, i+1)
+		} else {
+			body, i = plainDiagnosticBody(lines, i+1)
+		}
+		warnings = append(warnings, newWarning(strings.TrimPrefix(line, warningPrefix), body))
+	}
+	return warnings
+}
+
+// boxedDiagnosticBody returns the lines of a boxed diagnostic that follow its summary, without the box, and the index
+// of the line that ends the box.
+func boxedDiagnosticBody(lines []string, start int) ([]string, int) {
+	var body []string
+	i := start
+	for ; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \r")
+		if !strings.HasPrefix(line, diagnosticBoxLine) {
+			break
+		}
+		body = append(body, strings.TrimPrefix(strings.TrimPrefix(line, diagnosticBoxLine), " "))
+	}
+	return body, i
+}
+
+// plainDiagnosticBody returns the lines of a -no-color diagnostic that follow its summary and the index of its last
+// line. Without a box, the end of a diagnostic is only known from its shape: an optional source snippet, which starts
+// with "with" or "on", followed by a single paragraph of detail.
+func plainDiagnosticBody(lines []string, start int) ([]string, int) {
+	var body []string
+	paragraphs := 0
+	inParagraph := false
+	i := start
+	for ; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \r")
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" {
+			if inParagraph {
+				inParagraph = false
+				if paragraphs == 2 || (paragraphs == 1 && !isSnippet(body)) {
+					break
+				}
+			}
+			body = append(body, line)
+			continue
+		}
+		if strings.HasPrefix(line, warningPrefix) || strings.HasPrefix(line, "Error: ") || strings.HasPrefix(line, diagnosticBoxStart) {
+			break
+		}
+
+		if !inParagraph {
+			inParagraph = true
+			paragraphs++
+		}
+		body = append(body, line)
+	}
+	return body, i - 1
+}
+
+// isSnippet returns true if the first paragraph of the body is a source snippet.
+func isSnippet(body []string) bool {
+	for _, line := range body {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" {
+			return strings.HasPrefix(trimmed, "with ") || strings.HasPrefix(trimmed, "on ")
+		}
+	}
+	return false
+}
+
+// newWarning builds a warning from its summary and the lines that follow it: an optional source snippet, whose "with"
+// line holds the address of the resource, and the detail.
+func newWarning(summary string, body []string) Warning {
+	warning := Warning{Summary: strings.TrimSpace(summary)}
+
+	i := 0
+	for i < len(body) && strings.TrimSpace(body[i]) == "" {
+		i++
+	}
+	if isSnippet(body) {
+		for ; i < len(body) && strings.TrimSpace(body[i]) != ""; i++ {
+			trimmed := strings.TrimSpace(body[i])
+			if strings.HasPrefix(trimmed, "with ") {
+				warning.Address = strings.TrimSuffix(strings.TrimPrefix(trimmed, "with "), ",")
+			}
+		}
+	}
+
+	warning.Detail = strings.TrimSpace(strings.Join(body[i:], "\n"))
+	return warning
+}
+
+// AssertNoDeprecationWarnings checks that terraform printed no deprecation warnings while running the command, so that
+// deprecated provider arguments are caught before they become errors.
+func AssertNoDeprecationWarnings(t testing.TestingT, result *CommandResult) bool {
+	return assert.NoError(t, AssertNoDeprecationWarningsE(t, result))
+}
+
+// AssertNoDeprecationWarningsE checks that terraform printed no deprecation warnings while running the command.
+func AssertNoDeprecationWarningsE(t testing.TestingT, result *CommandResult) error {
+	var deprecations []Warning
+	for _, warning := range result.Warnings {
+		if warning.IsDeprecation() {
+			deprecations = append(deprecations, warning)
+		}
+	}
+
+	if len(deprecations) > 0 {
+		return DeprecationWarningsFound(deprecations)
+	}
+	return nil
+}
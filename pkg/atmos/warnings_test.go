@@ -0,0 +1,100 @@
+package atmos
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const boxedWarnings = `aws_s3_bucket.default[0]: Refreshing state... [id=test-bucket]
+╷
+│ Warning: Argument is deprecated
+│
+│   with aws_s3_bucket.default[0],
+│   on main.tf line 12, in resource "aws_s3_bucket" "default":
+│   12:   acl = "private"
+│
+│ Use the aws_s3_bucket_acl resource instead
+│
+│ (and one more similar warning elsewhere)
+╵
+╷
+│ Warning: Value for undeclared variable
+│
+│ The root module does not declare a variable named "region" but a value was found in file "default-test-vpc.terraform.tfvars.json".
+╵
+
+No changes. Your infrastructure matches the configuration.
+`
+
+const plainWarnings = `Terraform will perform the following actions:
+
+Plan: 1 to add, 0 to change, 0 to destroy.
+
+Warning: Deprecated attribute
+
+  on main.tf line 20, in output "arn":
+  20:   value = module.bucket.bucket_arn
+
+The attribute "bucket_arn" is deprecated. Refer to the provider documentation
+for details.
+
+Warning: Version constraints inside provider configuration blocks are deprecated
+
+  on providers.tf line 3, in provider "aws":
+   3:   version = "~> 5.0"
+
+Terraform 0.13 and earlier allowed provider version constraints inside the
+provider configuration block, but that is now deprecated.
+
+Apply complete! Resources: 1 added, 0 changed, 0 destroyed.
+`
+
+func TestParseWarningsBoxed(t *testing.T) {
+	warnings := ParseWarnings(boxedWarnings)
+
+	require.Len(t, warnings, 2)
+	assert.Equal(t, Warning{
+		Summary: "Argument is deprecated",
+		Detail:  "Use the aws_s3_bucket_acl resource instead\n\n(and one more similar warning elsewhere)",
+		Address: "aws_s3_bucket.default[0]",
+	}, warnings[0])
+	assert.Equal(t, "Value for undeclared variable", warnings[1].Summary)
+	assert.Contains(t, warnings[1].Detail, `declare a variable named "region"`)
+	assert.Empty(t, warnings[1].Address)
+}
+
+func TestParseWarningsPlain(t *testing.T) {
+	warnings := ParseWarnings(plainWarnings)
+
+	require.Len(t, warnings, 2)
+	assert.Equal(t, Warning{
+		Summary: "Deprecated attribute",
+		Detail:  "The attribute \"bucket_arn\" is deprecated. Refer to the provider documentation\nfor details.",
+	}, warnings[0])
+	assert.Equal(t, "Version constraints inside provider configuration blocks are deprecated", warnings[1].Summary)
+	assert.NotContains(t, warnings[1].Detail, "Apply complete!")
+}
+
+func TestParseWarningsNone(t *testing.T) {
+	assert.Empty(t, ParseWarnings("No changes. Your infrastructure matches the configuration.\n"))
+}
+
+func TestAssertNoDeprecationWarnings(t *testing.T) {
+	options := &Options{Executor: NewFakeExecutor(FakeResponse{Stdout: boxedWarnings})}
+
+	result, err := RunAtmosCommandResultE(t, options, "terraform", "plan", "vpc")
+	require.NoError(t, err)
+	require.Len(t, result.Warnings, 2)
+
+	err = AssertNoDeprecationWarningsE(t, result)
+	var deprecations DeprecationWarningsFound
+	require.True(t, errors.As(err, &deprecations))
+	require.Len(t, deprecations, 1)
+	assert.Equal(t, "aws_s3_bucket.default[0]", deprecations[0].Address)
+	assert.Contains(t, err.Error(), "aws_s3_bucket.default[0]: Argument is deprecated")
+
+	assert.NoError(t, AssertNoDeprecationWarningsE(t, &CommandResult{Warnings: result.Warnings[1:]}))
+}
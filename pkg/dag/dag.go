@@ -0,0 +1,184 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// errNodeAborted is reported for a node whose function exited without returning, e.g. because it called
+// runtime.Goexit through t.FailNow.
+var errNodeAborted = errors.New("node did not run to completion")
+
+// CycleError is returned when the graph contains a cycle. Nodes lists the nodes that could not be ordered because they
+// are part of, or depend on, a cycle.
+type CycleError struct {
+	Nodes []int
+}
+
+func (err CycleError) Error() string {
+	return fmt.Sprintf("dependency graph contains a cycle between nodes %v", err.Nodes)
+}
+
+// Graph is a directed acyclic graph of nodes identified by their index. An edge from one node to another means that
+// the first node depends on the second one.
+type Graph struct {
+	dependencies [][]int
+	dependents   [][]int
+}
+
+// New creates a graph with n nodes and no edges.
+func New(n int) *Graph {
+	return &Graph{
+		dependencies: make([][]int, n),
+		dependents:   make([][]int, n),
+	}
+}
+
+// Len returns the number of nodes in the graph.
+func (g *Graph) Len() int {
+	return len(g.dependencies)
+}
+
+// AddEdge records that node depends on dependsOn.
+func (g *Graph) AddEdge(node int, dependsOn int) {
+	for _, d := range g.dependencies[node] {
+		if d == dependsOn {
+			return
+		}
+	}
+	g.dependencies[node] = append(g.dependencies[node], dependsOn)
+	g.dependents[dependsOn] = append(g.dependents[dependsOn], node)
+}
+
+// DependsOn returns the nodes the given node directly depends on.
+func (g *Graph) DependsOn(node int) []int {
+	return g.dependencies[node]
+}
+
+// TopologicalOrder returns the nodes ordered so that every node comes after the nodes it depends on. Among nodes that
+// are ready at the same time, the lowest index comes first, so a graph without edges is returned in index order.
+func (g *Graph) TopologicalOrder() ([]int, error) {
+	var order []int
+	err := walk(g.dependencies, g.dependents, 1, func(a, b int) bool { return a < b }, func(node int) error {
+		order = append(order, node)
+		return nil
+	})
+	return order, err
+}
+
+// Walk calls fn for every node, only once all the nodes it depends on have completed successfully. Up to workers nodes
+// are run concurrently; a limit below one is treated as one. With a single worker, nodes are visited in the order
+// returned by TopologicalOrder. After the first error no further nodes are started, the nodes already running are
+// waited for and the first error is returned.
+func (g *Graph) Walk(workers int, fn func(node int) error) error {
+	return walk(g.dependencies, g.dependents, workers, func(a, b int) bool { return a < b }, fn)
+}
+
+// ReverseWalk is like Walk but visits the graph in reverse: fn is only called for a node once every node depending on
+// it has completed. With a single worker and no edges, nodes are visited from the highest index to the lowest.
+func (g *Graph) ReverseWalk(workers int, fn func(node int) error) error {
+	return walk(g.dependents, g.dependencies, workers, func(a, b int) bool { return a > b }, fn)
+}
+
+type result struct {
+	node int
+	err  error
+}
+
+// walk schedules fn over the nodes of the graph described by the before (nodes that must complete first) and after
+// (nodes unblocked on completion) adjacency lists. The graph is checked for cycles before any node is run.
+func walk(before [][]int, after [][]int, workers int, less func(a, b int) bool, fn func(node int) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	if err := checkCycles(before, after); err != nil {
+		return err
+	}
+
+	pending := make([]int, len(before))
+	var ready []int
+	for node, deps := range before {
+		pending[node] = len(deps)
+		if len(deps) == 0 {
+			ready = append(ready, node)
+		}
+	}
+
+	results := make(chan result)
+	running := 0
+	var firstErr error
+
+	for {
+		sort.Slice(ready, func(i, j int) bool { return less(ready[i], ready[j]) })
+		for firstErr == nil && running < workers && len(ready) > 0 {
+			node := ready[0]
+			ready = ready[1:]
+			running++
+			go func(node int) {
+				err := errNodeAborted
+				defer func() { results <- result{node: node, err: err} }()
+				err = fn(node)
+			}(node)
+		}
+
+		if running == 0 {
+			return firstErr
+		}
+
+		r := <-results
+		running--
+		if r.err != nil {
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			continue
+		}
+
+		for _, next := range after[r.node] {
+			pending[next]--
+			if pending[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+}
+
+// checkCycles runs Kahn's algorithm over the graph and returns a CycleError listing the nodes that could not be
+// ordered.
+func checkCycles(before [][]int, after [][]int) error {
+	pending := make([]int, len(before))
+	var queue []int
+	for node, deps := range before {
+		pending[node] = len(deps)
+		if len(deps) == 0 {
+			queue = append(queue, node)
+		}
+	}
+
+	visited := 0
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, next := range after[node] {
+			pending[next]--
+			if pending[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	if visited == len(before) {
+		return nil
+	}
+
+	var nodes []int
+	for node, count := range pending {
+		if count > 0 {
+			nodes = append(nodes, node)
+		}
+	}
+	return CycleError{Nodes: nodes}
+}
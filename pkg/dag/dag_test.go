@@ -0,0 +1,134 @@
+package dag
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopologicalOrderWithoutEdgesKeepsIndexOrder(t *testing.T) {
+	order, err := New(4).TopologicalOrder()
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3}, order)
+}
+
+func TestTopologicalOrderRespectsEdges(t *testing.T) {
+	g := New(4)
+	g.AddEdge(0, 2) // 0 depends on 2
+	g.AddEdge(2, 3) // 2 depends on 3
+
+	order, err := g.TopologicalOrder()
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 3, 2, 0}, order)
+}
+
+func TestTopologicalOrderDetectsCycles(t *testing.T) {
+	g := New(3)
+	g.AddEdge(0, 1)
+	g.AddEdge(1, 2)
+	g.AddEdge(2, 1)
+
+	_, err := g.TopologicalOrder()
+	var cycleErr CycleError
+	require.True(t, errors.As(err, &cycleErr))
+	assert.Equal(t, []int{0, 1, 2}, cycleErr.Nodes)
+}
+
+func TestWalkDoesNotRunAnythingWhenThereIsACycle(t *testing.T) {
+	g := New(3)
+	g.AddEdge(1, 2)
+	g.AddEdge(2, 1)
+
+	called := false
+	err := g.Walk(4, func(node int) error {
+		called = true
+		return nil
+	})
+	require.Error(t, err)
+	assert.False(t, called)
+}
+
+func TestReverseWalkWithoutEdgesVisitsHighestIndexFirst(t *testing.T) {
+	var order []int
+	err := New(3).ReverseWalk(1, func(node int) error {
+		order = append(order, node)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 1, 0}, order)
+}
+
+func TestReverseWalkVisitsDependentsFirst(t *testing.T) {
+	g := New(3)
+	g.AddEdge(0, 1)
+	g.AddEdge(1, 2)
+
+	var order []int
+	err := g.ReverseWalk(3, func(node int) error {
+		order = append(order, node)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, order)
+}
+
+func TestWalkRunsIndependentNodesConcurrently(t *testing.T) {
+	g := New(4)
+	g.AddEdge(3, 0)
+	g.AddEdge(3, 1)
+	g.AddEdge(3, 2)
+
+	var running, maxRunning int32
+	var mu sync.Mutex
+	done := map[int]bool{}
+
+	err := g.Walk(2, func(node int) error {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			seen := atomic.LoadInt32(&maxRunning)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, current) {
+				break
+			}
+		}
+
+		mu.Lock()
+		if node == 3 {
+			assert.True(t, done[0] && done[1] && done[2], "node 3 started before its dependencies completed")
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		done[node] = true
+		mu.Unlock()
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), maxRunning)
+	assert.Len(t, done, 4)
+}
+
+func TestWalkStopsSchedulingAfterAnError(t *testing.T) {
+	g := New(3)
+	g.AddEdge(1, 0)
+	g.AddEdge(2, 1)
+
+	expected := errors.New("boom")
+	var visited []int
+	err := g.Walk(1, func(node int) error {
+		visited = append(visited, node)
+		if node == 1 {
+			return expected
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, expected)
+	assert.Equal(t, []int{0, 1}, visited)
+}
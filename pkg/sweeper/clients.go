@@ -0,0 +1,48 @@
+package sweeper
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudcontrol"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3API is the subset of the S3 client used by the sweeper.
+type S3API interface {
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	ListObjectVersions(ctx context.Context, params *s3.ListObjectVersionsInput, optFns ...func(*s3.Options)) (*s3.ListObjectVersionsOutput, error)
+	DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error)
+	DeleteBucket(ctx context.Context, params *s3.DeleteBucketInput, optFns ...func(*s3.Options)) (*s3.DeleteBucketOutput, error)
+}
+
+// TaggingAPI is the subset of the Resource Groups Tagging API client used by the sweeper.
+type TaggingAPI interface {
+	GetResources(ctx context.Context, params *resourcegroupstaggingapi.GetResourcesInput, optFns ...func(*resourcegroupstaggingapi.Options)) (*resourcegroupstaggingapi.GetResourcesOutput, error)
+}
+
+// CloudControlAPI is the subset of the Cloud Control API client used by the sweeper.
+type CloudControlAPI interface {
+	DeleteResource(ctx context.Context, params *cloudcontrol.DeleteResourceInput, optFns ...func(*cloudcontrol.Options)) (*cloudcontrol.DeleteResourceOutput, error)
+	GetResourceRequestStatus(ctx context.Context, params *cloudcontrol.GetResourceRequestStatusInput, optFns ...func(*cloudcontrol.Options)) (*cloudcontrol.GetResourceRequestStatusOutput, error)
+}
+
+// Clients are the AWS clients the sweeper talks to.
+type Clients struct {
+	S3           S3API
+	Tagging      TaggingAPI
+	CloudControl CloudControlAPI
+}
+
+// NewClients creates the AWS clients from cfg. When cfg has a custom base endpoint, e.g. LocalStack, S3 is addressed
+// with path style requests.
+func NewClients(cfg aws.Config) *Clients {
+	return &Clients{
+		S3: s3.NewFromConfig(cfg, func(o *s3.Options) {
+			o.UsePathStyle = cfg.BaseEndpoint != nil
+		}),
+		Tagging:      resourcegroupstaggingapi.NewFromConfig(cfg),
+		CloudControl: cloudcontrol.NewFromConfig(cfg),
+	}
+}
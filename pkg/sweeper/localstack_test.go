@@ -0,0 +1,114 @@
+package sweeper
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/localstack"
+)
+
+// startLocalStack returns an AWS config pointing at the LocalStack instance in LOCALSTACK_ENDPOINT, or at a LocalStack
+// container started for the test. The test is skipped when neither is available.
+func startLocalStack(t *testing.T) aws.Config {
+	if testing.Short() {
+		t.Skip("skipping LocalStack test in short mode")
+	}
+
+	ctx := context.Background()
+	endpoint := os.Getenv("LOCALSTACK_ENDPOINT")
+	if endpoint == "" {
+		cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+		if err != nil {
+			t.Skipf("docker is not available: %s", err)
+		}
+		if _, err := cli.Ping(ctx); err != nil {
+			t.Skipf("docker is not available: %s", err)
+		}
+
+		container, err := localstack.Run(ctx, "localstack/localstack:4.2.0",
+			testcontainers.WithEnv(map[string]string{"SERVICES": "s3,resourcegroupstaggingapi"}),
+		)
+		testcontainers.CleanupContainer(t, container)
+		require.NoError(t, err)
+
+		endpoint, err = container.PortEndpoint(ctx, "4566/tcp", "http")
+		require.NoError(t, err)
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx,
+		config.WithRegion("us-east-1"),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider("test", "test", "")),
+		config.WithBaseEndpoint(endpoint),
+	)
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestSweepLocalStack(t *testing.T) {
+	cfg := startLocalStack(t)
+	ctx := context.Background()
+	s3Client := NewClients(cfg).S3.(*s3.Client)
+
+	createBucket := func(bucket string, tags map[string]string) {
+		_, err := s3Client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String(bucket)})
+		require.NoError(t, err)
+		if len(tags) == 0 {
+			return
+		}
+		var tagSet []types.Tag
+		for k, v := range tags {
+			tagSet = append(tagSet, types.Tag{Key: aws.String(k), Value: aws.String(v)})
+		}
+		_, err = s3Client.PutBucketTagging(ctx, &s3.PutBucketTaggingInput{
+			Bucket:  aws.String(bucket),
+			Tagging: &types.Tagging{TagSet: tagSet},
+		})
+		require.NoError(t, err)
+	}
+	putObject := func(bucket string, key string) {
+		_, err := s3Client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+			Body:   strings.NewReader("{}"),
+		})
+		require.NoError(t, err)
+	}
+
+	createBucket("tfstate", nil)
+	putObject("tfstate", "abc123-test-test-test/test-test-test/terraform.tfstate")
+	putObject("tfstate", "def456-test-test-test/test-test-test/terraform.tfstate")
+
+	createBucket("eg-test-abc123", map[string]string{"Attributes": "abc123"})
+	putObject("eg-test-abc123", "object")
+	createBucket("eg-test-def456", map[string]string{"Attributes": "def456"})
+
+	report, err := Sweep(ctx, &Options{
+		Identifiers: []string{"abc123"},
+		Buckets:     []string{"tfstate"},
+		AwsConfig:   &cfg,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"s3://tfstate/abc123-test-test-test/"}, report.S3Prefixes)
+	assert.Equal(t, []string{"arn:aws:s3:::eg-test-abc123"}, report.Resources)
+
+	prefixes, err := listTopLevelPrefixes(ctx, s3Client, "tfstate")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"def456-test-test-test/"}, prefixes)
+
+	_, err = s3Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String("eg-test-abc123")})
+	assert.Error(t, err)
+	_, err = s3Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String("eg-test-def456")})
+	assert.NoError(t, err)
+}
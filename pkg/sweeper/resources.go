@@ -0,0 +1,186 @@
+package sweeper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/cloudcontrol"
+	cctypes "github.com/aws/aws-sdk-go-v2/service/cloudcontrol/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	tagtypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/charmbracelet/log"
+)
+
+// deletePasses is how often deleting the remaining resources is attempted. Resources are deleted in no particular
+// order, so a resource that is still in use by another one, e.g. a VPC by its subnets, is retried in the next pass.
+const deletePasses = 3
+
+// cloudControlDeleteTimeout is how long to wait for Cloud Control to delete a single resource.
+const cloudControlDeleteTimeout = 15 * time.Minute
+
+// cloudControlResourceType maps an ARN resource prefix to a Cloud Control resource type.
+type cloudControlResourceType struct {
+	Service  string
+	Prefix   string
+	TypeName string
+	// UseARN uses the whole ARN as the identifier instead of the part of the resource after the prefix.
+	UseARN bool
+}
+
+var cloudControlResourceTypes = []cloudControlResourceType{
+	{Service: "dynamodb", Prefix: "table/", TypeName: "AWS::DynamoDB::Table"},
+	{Service: "ec2", Prefix: "internet-gateway/", TypeName: "AWS::EC2::InternetGateway"},
+	{Service: "ec2", Prefix: "natgateway/", TypeName: "AWS::EC2::NatGateway"},
+	{Service: "ec2", Prefix: "route-table/", TypeName: "AWS::EC2::RouteTable"},
+	{Service: "ec2", Prefix: "security-group/", TypeName: "AWS::EC2::SecurityGroup"},
+	{Service: "ec2", Prefix: "subnet/", TypeName: "AWS::EC2::Subnet"},
+	{Service: "ec2", Prefix: "vpc/", TypeName: "AWS::EC2::VPC"},
+	{Service: "ecr", Prefix: "repository/", TypeName: "AWS::ECR::Repository"},
+	{Service: "kms", Prefix: "key/", TypeName: "AWS::KMS::Key"},
+	{Service: "lambda", Prefix: "function:", TypeName: "AWS::Lambda::Function"},
+	{Service: "logs", Prefix: "log-group:", TypeName: "AWS::Logs::LogGroup"},
+	{Service: "secretsmanager", Prefix: "secret:", TypeName: "AWS::SecretsManager::Secret", UseARN: true},
+	{Service: "sns", Prefix: "", TypeName: "AWS::SNS::Topic", UseARN: true},
+}
+
+// errUnsupportedResource is returned for resources the sweeper does not know how to delete.
+var errUnsupportedResource = errors.New("unsupported resource type")
+
+// sweepTaggedResources deletes the AWS resources whose tags contain one of the identifiers.
+func sweepTaggedResources(ctx context.Context, clients *Clients, options *Options, report *Report) error {
+	arns, err := findTaggedResources(ctx, clients.Tagging, options.TagKeys, report.Identifiers)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, resourceARN := range arns {
+		if _, _, err := cloudControlResource(resourceARN); errors.Is(err, errUnsupportedResource) {
+			log.Warn("skipping resource the sweeper cannot delete", "arn", resourceARN)
+			report.Skipped = append(report.Skipped, resourceARN)
+			continue
+		}
+		report.Resources = append(report.Resources, resourceARN)
+		pending = append(pending, resourceARN)
+	}
+
+	if options.DryRun {
+		for _, resourceARN := range pending {
+			log.Info("would delete resource", "arn", resourceARN)
+		}
+		return nil
+	}
+
+	errs := map[string]error{}
+	for pass := 0; pass < deletePasses && len(pending) > 0; pass++ {
+		var failed []string
+		for _, resourceARN := range pending {
+			log.Info("deleting resource", "arn", resourceARN)
+			if err := deleteResource(ctx, clients, resourceARN); err != nil {
+				errs[resourceARN] = err
+				failed = append(failed, resourceARN)
+				continue
+			}
+			delete(errs, resourceARN)
+		}
+		pending = failed
+	}
+
+	for _, resourceARN := range pending {
+		report.addFailure(resourceARN, errs[resourceARN])
+	}
+	return nil
+}
+
+// findTaggedResources returns the ARNs of the resources with one of the tag keys set to a value that contains one of
+// the identifiers. The tagging API ANDs tag filters, so every key is queried separately.
+func findTaggedResources(ctx context.Context, client TaggingAPI, tagKeys []string, identifiers []string) ([]string, error) {
+	found := map[string]bool{}
+	for _, key := range tagKeys {
+		paginator := resourcegroupstaggingapi.NewGetResourcesPaginator(client, &resourcegroupstaggingapi.GetResourcesInput{
+			TagFilters: []tagtypes.TagFilter{{Key: aws.String(key)}},
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, mapping := range page.ResourceTagMappingList {
+				for _, tag := range mapping.Tags {
+					if aws.ToString(tag.Key) != key {
+						continue
+					}
+					if _, ok := matchIdentifier(aws.ToString(tag.Value), identifiers); ok {
+						found[aws.ToString(mapping.ResourceARN)] = true
+					}
+				}
+			}
+		}
+	}
+
+	arns := make([]string, 0, len(found))
+	for resourceARN := range found {
+		arns = append(arns, resourceARN)
+	}
+	sort.Strings(arns)
+	return arns, nil
+}
+
+func deleteResource(ctx context.Context, clients *Clients, resourceARN string) error {
+	typeName, identifier, err := cloudControlResource(resourceARN)
+	if err != nil {
+		return err
+	}
+
+	// Cloud Control refuses to delete buckets that are not empty, so buckets are emptied and deleted directly.
+	if typeName == "AWS::S3::Bucket" {
+		return deleteBucket(ctx, clients.S3, identifier)
+	}
+
+	out, err := clients.CloudControl.DeleteResource(ctx, &cloudcontrol.DeleteResourceInput{
+		TypeName:   aws.String(typeName),
+		Identifier: aws.String(identifier),
+	})
+	if err != nil {
+		return err
+	}
+	if out.ProgressEvent == nil || out.ProgressEvent.OperationStatus == cctypes.OperationStatusSuccess {
+		return nil
+	}
+
+	waiter := cloudcontrol.NewResourceRequestSuccessWaiter(clients.CloudControl)
+	return waiter.Wait(ctx, &cloudcontrol.GetResourceRequestStatusInput{
+		RequestToken: out.ProgressEvent.RequestToken,
+	}, cloudControlDeleteTimeout)
+}
+
+// cloudControlResource returns the Cloud Control resource type and identifier of the resource with the given ARN.
+func cloudControlResource(resourceARN string) (string, string, error) {
+	parsed, err := arn.Parse(resourceARN)
+	if err != nil {
+		return "", "", err
+	}
+
+	if parsed.Service == "s3" && !strings.Contains(parsed.Resource, "/") {
+		return "AWS::S3::Bucket", parsed.Resource, nil
+	}
+
+	for _, resourceType := range cloudControlResourceTypes {
+		if parsed.Service != resourceType.Service || !strings.HasPrefix(parsed.Resource, resourceType.Prefix) {
+			continue
+		}
+		if resourceType.UseARN {
+			return resourceType.TypeName, resourceARN, nil
+		}
+		identifier := strings.TrimPrefix(parsed.Resource, resourceType.Prefix)
+		return resourceType.TypeName, strings.TrimSuffix(identifier, ":*"), nil
+	}
+
+	return "", "", fmt.Errorf("%w: %s", errUnsupportedResource, resourceARN)
+}
@@ -0,0 +1,146 @@
+package sweeper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/charmbracelet/log"
+	"github.com/gruntwork-io/terratest/modules/collections"
+)
+
+// maxDeleteObjects is the maximum number of keys accepted by a single DeleteObjects request.
+const maxDeleteObjects = 1000
+
+// sweepWorkspacePrefixes deletes the workspace prefixes of the bucket that belong to one of the identifiers. The test
+// helpers set workspace_key_prefix to <RandomIdentifier>-<stack>, so the identifier is the first segment of each top
+// level prefix. When sweeping by age, the identifiers of the prefixes whose objects are all stale are added to
+// report.StaleIdentifiers, and those prefixes are only deleted, and their identifiers only swept, if
+// options.SweepStaleIdentifiers is set.
+func sweepWorkspacePrefixes(ctx context.Context, client S3API, bucket string, options *Options, report *Report) error {
+	prefixes, err := listTopLevelPrefixes(ctx, client, bucket)
+	if err != nil {
+		return err
+	}
+
+	for _, prefix := range prefixes {
+		identifier, _, _ := strings.Cut(strings.TrimSuffix(prefix, "/"), "-")
+		matched := collections.ListContains(report.Identifiers, identifier)
+		if !matched && (options.OlderThan <= 0 || !options.IdentifierPattern.MatchString(identifier)) {
+			continue
+		}
+
+		objects, latest, err := listObjectVersions(ctx, client, bucket, prefix)
+		if err != nil {
+			report.addError(fmt.Errorf("listing s3://%s/%s: %w", bucket, prefix, err))
+			continue
+		}
+		if !matched {
+			if !options.stale(latest) {
+				continue
+			}
+			report.StaleIdentifiers = append(report.StaleIdentifiers, identifier)
+			if !options.SweepStaleIdentifiers {
+				log.Info("keeping stale workspace prefix that was not given by identifier", "location", fmt.Sprintf("s3://%s/%s", bucket, prefix))
+				continue
+			}
+			report.Identifiers = append(report.Identifiers, identifier)
+		}
+
+		location := fmt.Sprintf("s3://%s/%s", bucket, prefix)
+		report.S3Prefixes = append(report.S3Prefixes, location)
+		if options.DryRun {
+			log.Info("would delete workspace prefix", "location", location, "objects", len(objects))
+			continue
+		}
+
+		log.Info("deleting workspace prefix", "location", location, "objects", len(objects))
+		if err := deleteObjects(ctx, client, bucket, objects); err != nil {
+			report.addFailure(location, err)
+		}
+	}
+	return nil
+}
+
+func listTopLevelPrefixes(ctx context.Context, client S3API, bucket string) ([]string, error) {
+	var prefixes []string
+	paginator := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(bucket),
+		Delimiter: aws.String("/"),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, prefix := range page.CommonPrefixes {
+			prefixes = append(prefixes, aws.ToString(prefix.Prefix))
+		}
+	}
+	return prefixes, nil
+}
+
+// listObjectVersions returns every version and delete marker under prefix, so that versioned state buckets are
+// emptied too, along with the time the prefix was last written to.
+func listObjectVersions(ctx context.Context, client S3API, bucket string, prefix string) ([]types.ObjectIdentifier, time.Time, error) {
+	var objects []types.ObjectIdentifier
+	var latest time.Time
+	add := func(key *string, versionID *string, lastModified *time.Time) {
+		objects = append(objects, types.ObjectIdentifier{Key: key, VersionId: versionID})
+		if lastModified != nil && lastModified.After(latest) {
+			latest = *lastModified
+		}
+	}
+
+	paginator := s3.NewListObjectVersionsPaginator(client, &s3.ListObjectVersionsInput{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, latest, err
+		}
+		for _, version := range page.Versions {
+			add(version.Key, version.VersionId, version.LastModified)
+		}
+		for _, marker := range page.DeleteMarkers {
+			add(marker.Key, marker.VersionId, marker.LastModified)
+		}
+	}
+	return objects, latest, nil
+}
+
+func deleteObjects(ctx context.Context, client S3API, bucket string, objects []types.ObjectIdentifier) error {
+	for start := 0; start < len(objects); start += maxDeleteObjects {
+		end := min(start+maxDeleteObjects, len(objects))
+		out, err := client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &types.Delete{Objects: objects[start:end], Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return err
+		}
+		if len(out.Errors) > 0 {
+			return fmt.Errorf("failed to delete %s: %s", aws.ToString(out.Errors[0].Key), aws.ToString(out.Errors[0].Message))
+		}
+	}
+	return nil
+}
+
+// deleteBucket empties the bucket and deletes it.
+func deleteBucket(ctx context.Context, client S3API, bucket string) error {
+	objects, _, err := listObjectVersions(ctx, client, bucket, "")
+	if err != nil {
+		return err
+	}
+	if err := deleteObjects(ctx, client, bucket, objects); err != nil {
+		return err
+	}
+	_, err = client.DeleteBucket(ctx, &s3.DeleteBucketInput{Bucket: aws.String(bucket)})
+	return err
+}
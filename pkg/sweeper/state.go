@@ -0,0 +1,105 @@
+package sweeper
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// sweepStateDirs deletes the local terraform state files under the state directories that mention one of the
+// identifiers or are stale, then removes the directories left empty.
+func sweepStateDirs(options *Options, report *Report) error {
+	for _, pattern := range options.StateDirs {
+		stateDirs, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid state dir pattern %q: %w", pattern, err)
+		}
+
+		for _, stateDir := range stateDirs {
+			stateFiles, err := findStateFiles(stateDir, options)
+			if err != nil {
+				report.addError(fmt.Errorf("sweeping state dir %s: %w", stateDir, err))
+				continue
+			}
+
+			for _, stateFile := range stateFiles {
+				report.StateFiles = append(report.StateFiles, stateFile)
+				if options.DryRun {
+					log.Info("would delete state file", "path", stateFile)
+					continue
+				}
+				log.Info("deleting state file", "path", stateFile)
+				if err := os.Remove(stateFile); err != nil {
+					report.addFailure(stateFile, err)
+				}
+			}
+
+			if !options.DryRun {
+				removeEmptyDirs(stateDir)
+			}
+		}
+	}
+	return nil
+}
+
+// findStateFiles returns the state files and state backups under stateDir that belong to one of the identifiers or
+// are stale.
+func findStateFiles(stateDir string, options *Options) ([]string, error) {
+	var stateFiles []string
+	err := filepath.WalkDir(stateDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isStateFile(d.Name()) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if options.stale(info.ModTime()) {
+			stateFiles = append(stateFiles, path)
+			return nil
+		}
+
+		if len(options.Identifiers) == 0 {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if _, ok := matchIdentifier(string(content), options.Identifiers); ok {
+			stateFiles = append(stateFiles, path)
+		}
+		return nil
+	})
+	return stateFiles, err
+}
+
+func isStateFile(name string) bool {
+	return strings.HasSuffix(name, ".tfstate") || strings.HasSuffix(name, ".tfstate.backup")
+}
+
+// removeEmptyDirs removes the empty directories below root, deepest first. root itself is kept.
+func removeEmptyDirs(root string) {
+	var dirs []string
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && path != root {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i]) > len(dirs[j]) })
+	for _, dir := range dirs {
+		// os.Remove fails on directories that are not empty, which is what we want.
+		_ = os.Remove(dir)
+	}
+}
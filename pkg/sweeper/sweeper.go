@@ -0,0 +1,187 @@
+// Package sweeper finds and destroys what killed or crashed test runs leave behind. Every deployment made by the test
+// helpers carries the run's RandomIdentifier in its attributes, which ends up in resource names and tags, in the
+// workspace_key_prefix of the S3 backend and in the local state files under StateDir. The sweeper uses that identifier,
+// or the age of the leftovers, to find them.
+package sweeper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/charmbracelet/log"
+)
+
+// DefaultIdentifierPattern matches the identifiers generated by the test helpers, six lower case alphanumeric
+// characters.
+var DefaultIdentifierPattern = regexp.MustCompile(`^[a-z0-9]{6}$`)
+
+// DefaultTagKeys are the tags set by the cloudposse null label that contain the attributes of a resource.
+var DefaultTagKeys = []string{"Attributes", "Name"}
+
+// ErrorNothingToSweep is returned when neither identifiers nor an age threshold are given, since that would match
+// everything.
+var ErrorNothingToSweep = errors.New("at least one identifier or an age threshold is required")
+
+// Options configures a sweep.
+type Options struct {
+	// Identifiers are the RandomIdentifier values of the runs to clean up.
+	Identifiers []string
+
+	// OlderThan sweeps the local state files that were last modified longer ago than this, and looks for S3 workspace
+	// prefixes that were, reporting their identifiers in Report.StaleIdentifiers.
+	OlderThan time.Duration
+
+	// SweepStaleIdentifiers also sweeps the stale S3 workspace prefixes found with OlderThan, and the tagged resources
+	// of their identifiers. The identifier of a prefix is only inferred from its name, which any workspace_key_prefix
+	// could match, so this has to be opted into.
+	SweepStaleIdentifiers bool
+
+	// StateDirs are the state directories to sweep. Glob patterns such as /tmp/atmos-test-helper*/state are expanded.
+	StateDirs []string
+
+	// Buckets are the S3 backend buckets whose workspace prefixes are swept.
+	Buckets []string
+
+	// TagKeys are the tags that are searched for identifiers. Defaults to DefaultTagKeys.
+	TagKeys []string
+
+	// IdentifierPattern recognizes identifiers in workspace prefixes when looking for stale prefixes. Defaults to
+	// DefaultIdentifierPattern.
+	IdentifierPattern *regexp.Regexp
+
+	// SkipTaggedResources disables sweeping AWS resources through the Resource Groups Tagging API.
+	SkipTaggedResources bool
+
+	// DryRun reports what would be swept without deleting anything.
+	DryRun bool
+
+	// AwsConfig is used to create the AWS clients. When nil, the default config is loaded for Region. Point it at a
+	// LocalStack endpoint to sweep a LocalStack instance.
+	AwsConfig *aws.Config
+	Region    string
+
+	// Clients overrides the AWS clients created from AwsConfig.
+	Clients *Clients
+
+	now func() time.Time
+}
+
+// Report lists what a sweep found. Unless the sweep was a dry run, everything listed was deleted, apart from the
+// entries in Skipped and Failed.
+type Report struct {
+	Identifiers      []string
+	StaleIdentifiers []string // The identifiers inferred from stale workspace prefixes
+	StateFiles       []string
+	S3Prefixes       []string
+	Resources        []string
+	Skipped          []string
+	Failed           []string // The state files, workspace prefixes and resources that could not be deleted
+	Errors           []error
+}
+
+// Err joins the errors encountered during the sweep.
+func (r *Report) Err() error {
+	return errors.Join(r.Errors...)
+}
+
+func (r *Report) addError(err error) {
+	log.Warn("sweep error", "error", err)
+	r.Errors = append(r.Errors, err)
+}
+
+// addFailure records that deleting the given state file, workspace prefix or resource failed.
+func (r *Report) addFailure(item string, err error) {
+	r.Failed = append(r.Failed, item)
+	r.addError(fmt.Errorf("deleting %s: %w", item, err))
+}
+
+// Sweep finds the state files, S3 workspace prefixes and tagged AWS resources belonging to the given identifiers, or
+// older than the age threshold, and deletes them. Errors deleting individual leftovers do not stop the sweep; they are
+// collected in the report and returned joined.
+func Sweep(ctx context.Context, options *Options) (*Report, error) {
+	if len(options.Identifiers) == 0 && options.OlderThan <= 0 {
+		return nil, ErrorNothingToSweep
+	}
+
+	if options.TagKeys == nil {
+		options.TagKeys = DefaultTagKeys
+	}
+	if options.IdentifierPattern == nil {
+		options.IdentifierPattern = DefaultIdentifierPattern
+	}
+	if options.now == nil {
+		options.now = time.Now
+	}
+
+	report := &Report{Identifiers: append([]string{}, options.Identifiers...)}
+
+	if err := sweepStateDirs(options, report); err != nil {
+		return report, err
+	}
+
+	if len(options.Buckets) == 0 && options.SkipTaggedResources {
+		return report, report.Err()
+	}
+
+	clients, err := newClients(ctx, options)
+	if err != nil {
+		return report, err
+	}
+
+	for _, bucket := range options.Buckets {
+		if err := sweepWorkspacePrefixes(ctx, clients.S3, bucket, options, report); err != nil {
+			report.addError(fmt.Errorf("sweeping bucket %s: %w", bucket, err))
+		}
+	}
+
+	if !options.SkipTaggedResources && len(report.Identifiers) > 0 {
+		if err := sweepTaggedResources(ctx, clients, options, report); err != nil {
+			report.addError(fmt.Errorf("sweeping tagged resources: %w", err))
+		}
+	}
+
+	return report, report.Err()
+}
+
+func newClients(ctx context.Context, options *Options) (*Clients, error) {
+	if options.Clients != nil {
+		return options.Clients, nil
+	}
+
+	cfg := options.AwsConfig
+	if cfg == nil {
+		loaded, err := config.LoadDefaultConfig(ctx, config.WithRegion(options.Region))
+		if err != nil {
+			return nil, err
+		}
+		cfg = &loaded
+	}
+
+	return NewClients(*cfg), nil
+}
+
+// matchIdentifier returns the first identifier that appears as a whole token of value, e.g. abc123 matches
+// eg-test-abc123 and abc123-vpc but not xabc123.
+func matchIdentifier(value string, identifiers []string) (string, bool) {
+	tokens := strings.FieldsFunc(value, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	for _, identifier := range identifiers {
+		for _, token := range tokens {
+			if strings.EqualFold(token, identifier) {
+				return identifier, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (o *Options) stale(modTime time.Time) bool {
+	return o.OlderThan > 0 && o.now().Sub(modTime) > o.OlderThan
+}
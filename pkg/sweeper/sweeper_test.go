@@ -0,0 +1,268 @@
+package sweeper
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	tagtypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeStateFile(t *testing.T, path string, content string, modTime time.Time) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestSweepRequiresIdentifiersOrAge(t *testing.T) {
+	_, err := Sweep(context.Background(), &Options{StateDirs: []string{t.TempDir()}})
+	assert.ErrorIs(t, err, ErrorNothingToSweep)
+}
+
+func TestSweepStateDirsByIdentifier(t *testing.T) {
+	stateDir := filepath.Join(t.TempDir(), "state")
+	leftover := filepath.Join(stateDir, "target", "test-test-test", "terraform.tfstate")
+	other := filepath.Join(stateDir, "target", "other", "terraform.tfstate")
+	writeStateFile(t, leftover, `{"resources": [{"instances": [{"attributes": {"id": "eg-test-abc123"}}]}]}`, time.Now())
+	writeStateFile(t, other, `{"resources": [{"instances": [{"attributes": {"id": "eg-test-xabc123"}}]}]}`, time.Now())
+
+	report, err := Sweep(context.Background(), &Options{
+		Identifiers:         []string{"abc123"},
+		StateDirs:           []string{stateDir},
+		SkipTaggedResources: true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{leftover}, report.StateFiles)
+	assert.NoFileExists(t, leftover)
+	assert.NoDirExists(t, filepath.Dir(leftover))
+	assert.FileExists(t, other)
+	assert.DirExists(t, stateDir)
+}
+
+func TestSweepStateDirsByAge(t *testing.T) {
+	root := t.TempDir()
+	stale := filepath.Join(root, "atmos-test-helper1", "state", "vpc", "terraform.tfstate")
+	fresh := filepath.Join(root, "atmos-test-helper2", "state", "vpc", "terraform.tfstate")
+	writeStateFile(t, stale, "{}", time.Now().Add(-48*time.Hour))
+	writeStateFile(t, fresh, "{}", time.Now())
+
+	report, err := Sweep(context.Background(), &Options{
+		OlderThan:           24 * time.Hour,
+		StateDirs:           []string{filepath.Join(root, "atmos-test-helper*", "state")},
+		SkipTaggedResources: true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{stale}, report.StateFiles)
+	assert.NoFileExists(t, stale)
+	assert.FileExists(t, fresh)
+}
+
+func TestSweepStateDirsDryRun(t *testing.T) {
+	stateDir := t.TempDir()
+	leftover := filepath.Join(stateDir, "target", "terraform.tfstate.backup")
+	writeStateFile(t, leftover, `"abc123"`, time.Now())
+
+	report, err := Sweep(context.Background(), &Options{
+		Identifiers:         []string{"abc123"},
+		StateDirs:           []string{stateDir},
+		SkipTaggedResources: true,
+		DryRun:              true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{leftover}, report.StateFiles)
+	assert.FileExists(t, leftover)
+}
+
+func TestMatchIdentifier(t *testing.T) {
+	identifiers := []string{"abc123", "def456"}
+
+	identifier, ok := matchIdentifier("eg-ue2-test-vpc-def456", identifiers)
+	assert.True(t, ok)
+	assert.Equal(t, "def456", identifier)
+
+	_, ok = matchIdentifier("abc123-vpc", identifiers)
+	assert.True(t, ok)
+
+	_, ok = matchIdentifier("xabc123", identifiers)
+	assert.False(t, ok)
+}
+
+func TestCloudControlResource(t *testing.T) {
+	tests := []struct {
+		arn        string
+		typeName   string
+		identifier string
+	}{
+		{"arn:aws:s3:::eg-test-abc123", "AWS::S3::Bucket", "eg-test-abc123"},
+		{"arn:aws:dynamodb:us-east-2:111111111111:table/eg-test-abc123", "AWS::DynamoDB::Table", "eg-test-abc123"},
+		{"arn:aws:ec2:us-east-2:111111111111:vpc/vpc-0123456789", "AWS::EC2::VPC", "vpc-0123456789"},
+		{"arn:aws:logs:us-east-2:111111111111:log-group:/aws/lambda/abc123:*", "AWS::Logs::LogGroup", "/aws/lambda/abc123"},
+		{"arn:aws:sns:us-east-2:111111111111:eg-test-abc123", "AWS::SNS::Topic", "arn:aws:sns:us-east-2:111111111111:eg-test-abc123"},
+	}
+	for _, tt := range tests {
+		typeName, identifier, err := cloudControlResource(tt.arn)
+		require.NoError(t, err, tt.arn)
+		assert.Equal(t, tt.typeName, typeName, tt.arn)
+		assert.Equal(t, tt.identifier, identifier, tt.arn)
+	}
+
+	_, _, err := cloudControlResource("arn:aws:elasticache:us-east-2:111111111111:cluster:abc123")
+	assert.ErrorIs(t, err, errUnsupportedResource)
+}
+
+type fakeS3 struct {
+	S3API
+	objects         map[string]map[string]time.Time
+	deleteBucketErr error
+}
+
+func (f *fakeS3) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	seen := map[string]bool{}
+	out := &s3.ListObjectsV2Output{}
+	for key := range f.objects[aws.ToString(params.Bucket)] {
+		prefix, _, _ := strings.Cut(key, "/")
+		if !seen[prefix] {
+			seen[prefix] = true
+			out.CommonPrefixes = append(out.CommonPrefixes, s3types.CommonPrefix{Prefix: aws.String(prefix + "/")})
+		}
+	}
+	sort.Slice(out.CommonPrefixes, func(i, j int) bool {
+		return aws.ToString(out.CommonPrefixes[i].Prefix) < aws.ToString(out.CommonPrefixes[j].Prefix)
+	})
+	return out, nil
+}
+
+func (f *fakeS3) ListObjectVersions(ctx context.Context, params *s3.ListObjectVersionsInput, optFns ...func(*s3.Options)) (*s3.ListObjectVersionsOutput, error) {
+	out := &s3.ListObjectVersionsOutput{}
+	for key, modTime := range f.objects[aws.ToString(params.Bucket)] {
+		if strings.HasPrefix(key, aws.ToString(params.Prefix)) {
+			out.Versions = append(out.Versions, s3types.ObjectVersion{Key: aws.String(key), LastModified: aws.Time(modTime)})
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeS3) DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	for _, object := range params.Delete.Objects {
+		delete(f.objects[aws.ToString(params.Bucket)], aws.ToString(object.Key))
+	}
+	return &s3.DeleteObjectsOutput{}, nil
+}
+
+func (f *fakeS3) DeleteBucket(ctx context.Context, params *s3.DeleteBucketInput, optFns ...func(*s3.Options)) (*s3.DeleteBucketOutput, error) {
+	if f.deleteBucketErr != nil {
+		return nil, f.deleteBucketErr
+	}
+	delete(f.objects, aws.ToString(params.Bucket))
+	return &s3.DeleteBucketOutput{}, nil
+}
+
+type fakeTagging struct {
+	resources map[string]map[string]string
+}
+
+func (f *fakeTagging) GetResources(ctx context.Context, params *resourcegroupstaggingapi.GetResourcesInput, optFns ...func(*resourcegroupstaggingapi.Options)) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+	out := &resourcegroupstaggingapi.GetResourcesOutput{}
+	for resourceARN, tags := range f.resources {
+		value, ok := tags[aws.ToString(params.TagFilters[0].Key)]
+		if !ok {
+			continue
+		}
+		out.ResourceTagMappingList = append(out.ResourceTagMappingList, tagtypes.ResourceTagMapping{
+			ResourceARN: aws.String(resourceARN),
+			Tags:        []tagtypes.Tag{{Key: params.TagFilters[0].Key, Value: aws.String(value)}},
+		})
+	}
+	return out, nil
+}
+
+func TestSweepOnlyReportsStaleIdentifiersByDefault(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	fakeS3 := &fakeS3{objects: map[string]map[string]time.Time{
+		"tfstate": {"abc123-test-test-test/test-test-test/terraform.tfstate": old},
+	}}
+	fakeTagging := &fakeTagging{resources: map[string]map[string]string{
+		"arn:aws:s3:::eg-test-abc123": {"Attributes": "abc123"},
+	}}
+
+	report, err := Sweep(context.Background(), &Options{
+		OlderThan: 24 * time.Hour,
+		Buckets:   []string{"tfstate"},
+		Clients:   &Clients{S3: fakeS3, Tagging: fakeTagging},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"abc123"}, report.StaleIdentifiers)
+	assert.Empty(t, report.Identifiers)
+	assert.Empty(t, report.S3Prefixes)
+	assert.Empty(t, report.Resources)
+	assert.Len(t, fakeS3.objects["tfstate"], 1)
+}
+
+func TestSweepDiscoversStaleIdentifiersFromWorkspacePrefixes(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	fakeS3 := &fakeS3{objects: map[string]map[string]time.Time{
+		"tfstate": {
+			"abc123-test-test-test/test-test-test/terraform.tfstate": old,
+			"def456-test-test-test/test-test-test/terraform.tfstate": time.Now(),
+			"vpc/plat-ue2-prod/terraform.tfstate":                    old,
+		},
+		"eg-test-abc123": {"object": time.Now()},
+	}}
+	fakeTagging := &fakeTagging{resources: map[string]map[string]string{
+		"arn:aws:s3:::eg-test-abc123":              {"Attributes": "abc123"},
+		"arn:aws:s3:::eg-test-def456":              {"Attributes": "def456"},
+		"arn:aws:iam::111111111111:role/eg-abc123": {"Name": "eg-test-abc123"},
+	}}
+
+	report, err := Sweep(context.Background(), &Options{
+		OlderThan:             24 * time.Hour,
+		SweepStaleIdentifiers: true,
+		Buckets:               []string{"tfstate"},
+		Clients:               &Clients{S3: fakeS3, Tagging: fakeTagging},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"abc123"}, report.Identifiers)
+	assert.Equal(t, []string{"abc123"}, report.StaleIdentifiers)
+	assert.Equal(t, []string{"s3://tfstate/abc123-test-test-test/"}, report.S3Prefixes)
+	assert.Equal(t, []string{"arn:aws:s3:::eg-test-abc123"}, report.Resources)
+	assert.Equal(t, []string{"arn:aws:iam::111111111111:role/eg-abc123"}, report.Skipped)
+
+	assert.Len(t, fakeS3.objects["tfstate"], 2, "fresh and unrecognized prefixes are kept")
+	assert.NotContains(t, fakeS3.objects, "eg-test-abc123")
+}
+
+func TestSweepReportsResourcesThatFailedToDelete(t *testing.T) {
+	fakeS3 := &fakeS3{
+		objects:         map[string]map[string]time.Time{"eg-test-abc123": {}},
+		deleteBucketErr: errors.New("BucketNotEmpty"),
+	}
+	fakeTagging := &fakeTagging{resources: map[string]map[string]string{
+		"arn:aws:s3:::eg-test-abc123": {"Attributes": "abc123"},
+	}}
+
+	report, err := Sweep(context.Background(), &Options{
+		Identifiers: []string{"abc123"},
+		Clients:     &Clients{S3: fakeS3, Tagging: fakeTagging},
+	})
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"arn:aws:s3:::eg-test-abc123"}, report.Resources)
+	assert.Equal(t, []string{"arn:aws:s3:::eg-test-abc123"}, report.Failed)
+	assert.ErrorContains(t, err, "deleting arn:aws:s3:::eg-test-abc123: BucketNotEmpty")
+}